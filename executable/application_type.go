@@ -0,0 +1,321 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak"
+)
+
+// The values of $BP_EXECUTABLE_JAR_APPLICATION_TYPE. Web applications get a web process, CLI applications do not and
+// auto chooses between the two depending on whether a server is found on the classpath.
+const (
+	ApplicationTypeAuto = "auto"
+	ApplicationTypeWeb  = "web"
+	ApplicationTypeCLI  = "cli"
+)
+
+// ServerStack identifies a server by the libraries and classes that make it up.
+type ServerStack struct {
+	// Name is the name of the server.
+	Name string
+
+	// Libraries are the names of JAR files of the server, without version and extension.
+	Libraries []string
+
+	// Classes are the paths of classes of the server, found in shaded JARs and exploded applications.
+	Classes []string
+
+	// References are the internal names of classes that the classes of an application refer to when it runs the
+	// server itself. They identify servers whose libraries and classes clients use as well, and are only searched for
+	// in the classes of the JAR file or exploded application outside of the packages of the references.
+	References []string
+}
+
+// ServerStacks are the servers whose presence makes an application a web application. Libraries that HTTP clients
+// depend on as well, such as netty-codec-http and vertx-core, are not used to identify servers. A library name ending
+// in * matches any library whose name starts with the rest of it, such as the Scala versions of a library.
+var ServerStacks = []ServerStack{
+	{
+		Name:      "Tomcat",
+		Libraries: []string{"tomcat-embed-core", "tomcat-catalina"},
+		Classes:   []string{"org/apache/catalina/startup/Tomcat.class"},
+	},
+	{
+		Name:      "Jetty",
+		Libraries: []string{"jetty-server"},
+		Classes:   []string{"org/eclipse/jetty/server/Server.class"},
+	},
+	{
+		Name:      "Reactor Netty",
+		Libraries: []string{"reactor-netty-http"},
+		Classes:   []string{"reactor/netty/http/server/HttpServer.class"},
+	},
+	{
+		Name:      "Undertow",
+		Libraries: []string{"undertow-core"},
+		Classes:   []string{"io/undertow/Undertow.class"},
+	},
+	{
+		Name:      "Helidon",
+		Libraries: []string{"helidon-webserver"},
+		Classes:   []string{"io/helidon/webserver/WebServer.class"},
+	},
+	{
+		Name:      "Quarkus",
+		Libraries: []string{"quarkus-vertx-http"},
+		Classes:   []string{"io/quarkus/vertx/http/runtime/VertxHttpRecorder.class"},
+	},
+	{
+		Name:      "Vert.x",
+		Libraries: []string{"vertx-web"},
+		Classes:   []string{"io/vertx/ext/web/Router.class"},
+	},
+	{
+		Name:      "Micronaut",
+		Libraries: []string{"micronaut-http-server-netty"},
+		Classes:   []string{"io/micronaut/http/server/netty/NettyHttpServer.class"},
+	},
+	{
+		Name:      "Ktor",
+		Libraries: []string{"ktor-server-*"},
+		Classes:   []string{"io/ktor/server/engine/ApplicationEngine.class"},
+	},
+	{
+		Name:      "Grizzly",
+		Libraries: []string{"grizzly-http-server"},
+		Classes:   []string{"org/glassfish/grizzly/http/server/HttpServer.class"},
+	},
+	{
+		Name:      "Akka HTTP",
+		Libraries: []string{"akka-http_*"},
+		Classes:   []string{"akka/http/scaladsl/server/Directives.class"},
+	},
+	{
+		Name:      "Pekko HTTP",
+		Libraries: []string{"pekko-http_*"},
+		Classes:   []string{"org/apache/pekko/http/scaladsl/server/Directives.class"},
+	},
+	{
+		Name:       "Netty",
+		References: []string{"io/netty/bootstrap/ServerBootstrap"},
+	},
+}
+
+// nestedLibraries are the directories that contain the libraries of an application, either within the executable JAR
+// or in an exploded application.
+var nestedLibraries = []string{"BOOT-INF/lib", "WEB-INF/lib", "lib"}
+
+// launcherLibraries are the directories next to a JAR file that contain libraries its launcher loads without listing
+// them in the Class-Path, such as those of a Quarkus fast-jar.
+var launcherLibraries = []string{"lib/main"}
+
+// ResolveApplicationType returns the configured $BP_EXECUTABLE_JAR_APPLICATION_TYPE.
+func ResolveApplicationType(cr libpak.ConfigurationResolver) (string, error) {
+	switch s, _ := cr.Resolve("BP_EXECUTABLE_JAR_APPLICATION_TYPE"); s {
+	case "", ApplicationTypeAuto:
+		return ApplicationTypeAuto, nil
+	case ApplicationTypeWeb, ApplicationTypeCLI:
+		return s, nil
+	default:
+		return "", fmt.Errorf("$BP_EXECUTABLE_JAR_APPLICATION_TYPE must be %s, %s or %s, not %q",
+			ApplicationTypeAuto, ApplicationTypeWeb, ApplicationTypeCLI, s)
+	}
+}
+
+// FindServerStack returns the name of the first of the ServerStacks found on the classpath of the application
+// described by m within fsys, or an empty name if there is none. The Class-Path, the libraries nested in the JAR file
+// or exploded application, the libraries loaded by the launcher of a JAR file, and the classes of the JAR file or
+// exploded application are searched.
+func FindServerStack(fsys fs.FS, m PackageMetadata) (string, error) {
+	var libraries []string
+	classes := map[string]bool{}
+
+	// the classes of the application are only read if a server is identified by references
+	classFS, err := fs.Sub(fsys, m.Path)
+	if err != nil {
+		return "", fmt.Errorf("unable to open %s\n%w", m.Path, err)
+	}
+	var classNames []string
+
+	if m.Mode == PackageModeJAR {
+		z, err := openJAR(fsys, m.Path)
+		if err != nil {
+			return "", fmt.Errorf("unable to open %s\n%w", m.Path, err)
+		}
+		defer z.Close()
+
+		for _, f := range z.File {
+			if nestedLibrary(f.Name) {
+				libraries = append(libraries, path.Base(f.Name))
+			} else if strings.HasSuffix(f.Name, ".class") {
+				classes[f.Name] = true
+				classNames = append(classNames, f.Name)
+			}
+		}
+		classFS = z.Reader
+
+		for _, l := range launcherLibraries {
+			jars, err := fs.Glob(fsys, path.Join(path.Dir(m.Path), l, "*.jar"))
+			if err != nil {
+				return "", fmt.Errorf("unable to list JARs in %s\n%w", l, err)
+			}
+			for _, j := range jars {
+				libraries = append(libraries, path.Base(j))
+			}
+		}
+	} else {
+		for _, l := range nestedLibraries {
+			jars, err := fs.Glob(fsys, path.Join(m.Path, l, "*.jar"))
+			if err != nil {
+				return "", fmt.Errorf("unable to list JARs in %s\n%w", l, err)
+			}
+			for _, j := range jars {
+				libraries = append(libraries, path.Base(j))
+			}
+		}
+	}
+
+	for _, e := range m.ClassPath {
+		libraries = append(libraries, path.Base(e))
+	}
+
+	for _, s := range ServerStacks {
+		for _, l := range libraries {
+			if isLibrary(l, s.Libraries) {
+				return s.Name, nil
+			}
+		}
+
+		for _, c := range s.Classes {
+			if m.Mode == PackageModeJAR {
+				if classes[c] {
+					return s.Name, nil
+				}
+			} else if _, err := fs.Stat(fsys, path.Join(m.Path, c)); err == nil {
+				return s.Name, nil
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return "", fmt.Errorf("unable to stat %s\n%w", c, err)
+			}
+		}
+
+		if len(s.References) == 0 {
+			continue
+		}
+		if m.Mode != PackageModeJAR && classNames == nil {
+			if classNames, err = findClasses(classFS); err != nil {
+				return "", err
+			}
+		}
+		if found, err := referencesAny(classFS, classNames, s.References); err != nil {
+			return "", err
+		} else if found {
+			return s.Name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// findClasses returns the paths of the classes within fsys.
+func findClasses(fsys fs.FS) ([]string, error) {
+	classes := []string{}
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && strings.HasSuffix(name, ".class") {
+			classes = append(classes, name)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to list classes\n%w", err)
+	}
+	return classes, nil
+}
+
+// referencesAny reports whether any of the classes within fsys outside of the packages of references refers to one of
+// references. A class refers to another through an entry of its constant pool holding the internal name of the other
+// class, a CONSTANT_Utf8 tag followed by the length of the name.
+func referencesAny(fsys fs.FS, classes []string, references []string) (bool, error) {
+	for _, c := range classes {
+		var entries [][]byte
+		for _, r := range references {
+			if !strings.HasSuffix(path.Dir(c), path.Dir(r)) {
+				entries = append(entries, append([]byte{1, byte(len(r) >> 8), byte(len(r))}, r...))
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		b, err := fs.ReadFile(fsys, c)
+		if err != nil {
+			return false, fmt.Errorf("unable to read %s\n%w", c, err)
+		}
+		for _, e := range entries {
+			if bytes.Contains(b, e) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func nestedLibrary(name string) bool {
+	if !strings.HasSuffix(name, ".jar") {
+		return false
+	}
+	for _, l := range nestedLibraries {
+		if path.Dir(name) == l {
+			return true
+		}
+	}
+	return false
+}
+
+// isLibrary reports whether the JAR file name is one of libraries, with or without a version and with or without the
+// group id prefix Quarkus adds, e.g. io.vertx.vertx-web-4.5.0.jar.
+func isLibrary(name string, libraries []string) bool {
+	name = strings.TrimSuffix(name, ".jar")
+	for _, l := range libraries {
+		for s, ok := name, true; ok; _, s, ok = strings.Cut(s, ".") {
+			if isArtifact(s, l) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isArtifact reports whether name is artifact, with or without a version, or starts with artifact up to a trailing *.
+func isArtifact(name string, artifact string) bool {
+	if p, ok := strings.CutSuffix(artifact, "*"); ok {
+		return strings.HasPrefix(name, p)
+	}
+	if name == artifact {
+		return true
+	}
+	v, ok := strings.CutPrefix(name, artifact+"-")
+	return ok && v != "" && v[0] >= '0' && v[0] <= '9'
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testApplicationType(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath string
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+	})
+
+	find := func(m executable.PackageMetadata) string {
+		name, err := executable.FindServerStack(os.DirFS(appPath), m)
+		Expect(err).NotTo(HaveOccurred())
+		return name
+	}
+
+	context("JAR file", func() {
+		jar := executable.PackageMetadata{Path: "app.jar", Mode: executable.PackageModeJAR}
+
+		it("finds a nested library", func() {
+			CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+				"BOOT-INF/lib/tomcat-embed-core-10.1.0.jar": "",
+				"BOOT-INF/classes/Main.class":               "",
+			})
+
+			Expect(find(jar)).To(Equal("Tomcat"))
+		})
+
+		it("finds a shaded class", func() {
+			CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+				"io/undertow/Undertow.class": "",
+				"Main.class":                 "",
+			})
+
+			Expect(find(jar)).To(Equal("Undertow"))
+		})
+
+		it("finds nothing in a CLI application", func() {
+			CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+				"BOOT-INF/lib/picocli-4.7.0.jar":              "",
+				"BOOT-INF/lib/tomcat-embed-core-extras-1.jar": "",
+				"Main.class": "",
+			})
+
+			Expect(find(jar)).To(BeEmpty())
+		})
+
+		it("finds nothing in an application with an HTTP client", func() {
+			CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+				"BOOT-INF/lib/netty-codec-http-4.1.100.Final.jar":   "",
+				"BOOT-INF/lib/vertx-core-4.5.0.jar":                 "",
+				"BOOT-INF/lib/vertx-web-client-4.5.0.jar":           "",
+				"io/netty/handler/codec/http/HttpServerCodec.class": "",
+				"io/vertx/core/http/HttpServer.class":               "",
+				"Main.class":                                        "",
+			})
+
+			Expect(find(jar)).To(BeEmpty())
+		})
+	})
+
+	context("other servers", func() {
+		jar := executable.PackageMetadata{Path: "app.jar", Mode: executable.PackageModeJAR}
+
+		it("finds servers by library", func() {
+			for _, c := range []struct{ library, name string }{
+				{"micronaut-http-server-netty-4.2.0.jar", "Micronaut"},
+				{"ktor-server-core-jvm-2.3.7.jar", "Ktor"},
+				{"grizzly-http-server-4.0.0.jar", "Grizzly"},
+				{"akka-http_2.13-10.5.3.jar", "Akka HTTP"},
+				{"pekko-http_3-1.0.0.jar", "Pekko HTTP"},
+			} {
+				CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+					"BOOT-INF/lib/" + c.library:   "",
+					"BOOT-INF/classes/Main.class": "",
+				})
+
+				Expect(find(jar)).To(Equal(c.name), c.library)
+			}
+		})
+
+		it("does not mistake a client for a server", func() {
+			CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+				"BOOT-INF/lib/ktor-client-core-jvm-2.3.7.jar":    "",
+				"BOOT-INF/lib/akka-http-core_2.13-10.5.3.jar":    "",
+				"BOOT-INF/lib/netty-transport-4.1.100.Final.jar": "",
+				"BOOT-INF/classes/Main.class":                    classReferring("Main", "io/netty/bootstrap/Bootstrap"),
+			})
+
+			Expect(find(jar)).To(BeEmpty())
+		})
+
+		it("finds a plain Netty server started by the application", func() {
+			CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+				"BOOT-INF/lib/netty-transport-4.1.100.Final.jar": "",
+				"BOOT-INF/classes/Main.class":                    classReferring("Main", "io/netty/bootstrap/ServerBootstrap"),
+			})
+
+			Expect(find(jar)).To(Equal("Netty"))
+		})
+
+		it("does not mistake shaded Netty for a Netty server", func() {
+			CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+				"io/netty/bootstrap/ServerBootstrap.class": classReferring("io/netty/bootstrap/ServerBootstrap", "io/netty/bootstrap/ServerBootstrap"),
+				"Main.class": classReferring("Main", "io/netty/bootstrap/Bootstrap"),
+			})
+
+			Expect(find(jar)).To(BeEmpty())
+		})
+
+		it("finds a plain Netty server started by an exploded application", func() {
+			Expect(os.MkdirAll(filepath.Join(appPath, "com", "example"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "com", "example", "Main.class"),
+				[]byte(classReferring("com/example/Main", "io/netty/bootstrap/ServerBootstrap")), 0644)).To(Succeed())
+
+			Expect(find(executable.PackageMetadata{Path: ".", Mode: executable.PackageModeExploded})).To(Equal("Netty"))
+		})
+	})
+
+	context("Quarkus", func() {
+		it("finds the libraries of a fast-jar", func() {
+			Expect(os.MkdirAll(filepath.Join(appPath, "quarkus-app", "lib", "main"), 0755)).To(Succeed())
+			CreateZIP(t, filepath.Join(appPath, "quarkus-app", "quarkus-run.jar"), map[string]string{
+				"META-INF/MANIFEST.MF": "Main-Class: io.quarkus.bootstrap.runner.QuarkusEntryPoint\n" +
+					"Class-Path: lib/boot/io.quarkus.quarkus-bootstrap-runner-3.6.0.jar\n",
+			})
+			for _, l := range []string{"io.netty.netty-codec-http-4.1.100.Final.jar", "io.vertx.vertx-web-4.5.0.jar"} {
+				Expect(os.WriteFile(filepath.Join(appPath, "quarkus-app", "lib", "main", l), []byte{}, 0644)).To(Succeed())
+			}
+
+			Expect(find(executable.PackageMetadata{
+				Path:      "quarkus-app/quarkus-run.jar",
+				Mode:      executable.PackageModeJAR,
+				ClassPath: []string{"lib/boot/io.quarkus.quarkus-bootstrap-runner-3.6.0.jar"},
+			})).To(Equal("Vert.x"))
+		})
+
+		it("finds the Class-Path entries of a legacy-jar", func() {
+			CreateZIP(t, filepath.Join(appPath, "app-runner.jar"), map[string]string{
+				"META-INF/MANIFEST.MF": "Main-Class: io.quarkus.runner.GeneratedMain\n",
+			})
+
+			Expect(find(executable.PackageMetadata{
+				Path: "app-runner.jar",
+				Mode: executable.PackageModeJAR,
+				ClassPath: []string{
+					"lib/io.netty.netty-codec-http-4.1.100.Final.jar",
+					"lib/io.quarkus.quarkus-vertx-http-3.6.0.jar",
+				},
+			})).To(Equal("Quarkus"))
+		})
+
+		it("does not mistake a group id for a library", func() {
+			Expect(find(executable.PackageMetadata{
+				Path:      ".",
+				Mode:      executable.PackageModeExploded,
+				ClassPath: []string{"lib/io.vertx.vertx-web-client-4.5.0.jar", "lib/vertx-web.io.other-1.0.jar"},
+			})).To(BeEmpty())
+		})
+	})
+
+	context("exploded application", func() {
+		exploded := executable.PackageMetadata{Path: ".", Mode: executable.PackageModeExploded}
+
+		it("finds a nested library", func() {
+			Expect(os.MkdirAll(filepath.Join(appPath, "lib"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "lib", "jetty-server-11.jar"), []byte{}, 0644)).To(Succeed())
+
+			Expect(find(exploded)).To(Equal("Jetty"))
+		})
+
+		it("finds a class", func() {
+			Expect(os.MkdirAll(filepath.Join(appPath, "io", "vertx", "ext", "web"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "io", "vertx", "ext", "web", "Router.class"), []byte{}, 0644)).To(Succeed())
+
+			Expect(find(exploded)).To(Equal("Vert.x"))
+		})
+
+		it("finds a Class-Path entry", func() {
+			exploded.ClassPath = []string{"libs/reactor-netty-http-1.1.13.jar"}
+
+			Expect(find(exploded)).To(Equal("Reactor Netty"))
+		})
+
+		it("finds nothing in a CLI application", func() {
+			Expect(os.MkdirAll(filepath.Join(appPath, "lib"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "lib", "jetty-server-extras.jar"), []byte{}, 0644)).To(Succeed())
+
+			Expect(find(executable.PackageMetadata{Path: ".", Mode: executable.PackageModeExploded})).To(BeEmpty())
+		})
+	})
+
+	context("$BP_EXECUTABLE_JAR_APPLICATION_TYPE", func() {
+		it("defaults to auto", func() {
+			Expect(executable.ResolveApplicationType(libpak.ConfigurationResolver{})).To(Equal(executable.ApplicationTypeAuto))
+		})
+
+		it("fails for an unknown type", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_APPLICATION_TYPE", "desktop")
+
+			_, err := executable.ResolveApplicationType(libpak.ConfigurationResolver{})
+			Expect(err).To(MatchError(ContainSubstring("must be auto, web or cli")))
+		})
+	})
+}
+
+// classReferring returns the start of a class file named name whose constant pool refers to the class reference.
+func classReferring(name string, reference string) string {
+	b := []byte{0xca, 0xfe, 0xba, 0xbe, 0x00, 0x00, 0x00, 0x34, 0x00, 0x05}
+	for i, n := range []string{name, reference} {
+		// CONSTANT_Class pointing at the CONSTANT_Utf8 that follows it
+		b = append(b, 7, 0, byte(2*i+2))
+		b = append(b, 1, byte(len(n)>>8), byte(len(n)))
+		b = append(b, n...)
+	}
+	return string(b)
+}
@@ -17,12 +17,15 @@
 package executable
 
 import (
+	"crypto/x509"
+	"errors"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/paketo-buildpacks/libpak/bindings"
 	"github.com/paketo-buildpacks/libpak/effect"
 	"github.com/paketo-buildpacks/libpak/sbom"
 
@@ -32,25 +35,29 @@ import (
 )
 
 type Build struct {
-	Logger      bard.Logger
+	Logger bard.Logger
+
+	// SBOMScanner writes the launch SBOM, the scanner selected by $BP_EXECUTABLE_JAR_SBOM if nil.
 	SBOMScanner sbom.SBOMScanner
+
+	// Locators are the strategies used to find the executable JAR, DefaultLocators if nil.
+	Locators []Locator
 }
 
 func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 	result := libcnb.NewBuildResult()
 
-	cr, err := libpak.NewConfigurationResolver(context.Buildpack, nil)
+	cr, err := NewConfigurationResolver(context.Buildpack, context.Application.Path, nil)
 	if err != nil {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to create configuration resolver\n%w", err)
 	}
 
-	jarGlob, _ := cr.Resolve("BP_EXECUTABLE_JAR_LOCATION")
-	execJar, err := LoadExecutableJAR(context.Application.Path, jarGlob)
-	if err != nil {
+	lc := NewLocatorContext(cr)
+	lc.Logger = b.Logger
+	execJar, err := loadExecutableJAR(context.Application.Path, lc, b.Locators)
+	if err != nil && !errors.Is(err, ErrNoExecutableJAR) {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to load executable JAR\n%w", err)
-	}
-
-	if !execJar.Executable {
+	} else if err != nil {
 		for _, entry := range context.Plan.Entries {
 			result.Unmet = append(result.Unmet, libcnb.UnmetPlanEntry{Name: entry.Name})
 		}
@@ -59,7 +66,7 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 
 	b.Logger.Title(context.Buildpack)
 
-	cr, err = libpak.NewConfigurationResolver(context.Buildpack, nil)
+	cr, err = NewConfigurationResolver(context.Buildpack, context.Application.Path, nil)
 	if err != nil {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to create configuration resolver\n%w", err)
 	}
@@ -76,10 +83,33 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 		}
 	}
 
+	liveReload := launch && cr.ResolveBool("BP_LIVE_RELOAD_ENABLED")
+	if liveReload && !execJar.ExplodedJAR && cr.ResolveBool("BP_LIVE_RELOAD_EXPLODE") {
+		b.Logger.Bodyf("Exploding %s for live reload", execJar.Path)
+		if execJar, err = ExplodeExecutableJAR(context.Application.Path, execJar); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to explode executable JAR\n%w", err)
+		}
+	}
+
+	var cp []string
+	if execJar.ExplodedJAR {
+		cp = []string{context.Application.Path}
+		if s, ok := execJar.Properties.Get("Class-Path"); ok {
+			cp = append(cp, strings.Split(s, " ")...)
+		}
+	}
+
 	if launch {
 		command := "java"
 		arguments := []string{}
 
+		s, _ := cr.Resolve("BP_EXECUTABLE_JAR_JVM_OPTIONS")
+		jvmOptions, err := splitArguments(s)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to parse $BP_EXECUTABLE_JAR_JVM_OPTIONS\n%w", err)
+		}
+		arguments = append(arguments, jvmOptions...)
+
 		if execJar.ExplodedJAR {
 			arguments = append(arguments, execJar.MainClass)
 		} else {
@@ -93,73 +123,545 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 				Arguments: arguments,
 				Direct:    true,
 			},
-			libcnb.Process{
-				Type:      "task",
-				Command:   command,
-				Arguments: arguments,
-				Direct:    true,
-			},
-			libcnb.Process{
-				Type:      "web",
-				Command:   command,
-				Arguments: arguments,
-				Direct:    true,
-				Default:   true,
-			},
 		)
 
-		if cr.ResolveBool("BP_LIVE_RELOAD_ENABLED") {
-			if !execJar.ExplodedJAR {
-				b.Logger.Body("WARNING: Live Reload has been enabled, however, your command is set to run from a JAR file. This may fail or be a worse experience as the entire JAR file must change to trigger a reload.")
+		aliases := true
+		if s, _ := cr.Resolve("BP_EXECUTABLE_JAR_ALIASES_ENABLED"); s != "" {
+			if aliases, err = strconv.ParseBool(s); err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to parse $BP_EXECUTABLE_JAR_ALIASES_ENABLED %q\n%w", s, err)
 			}
+		}
+
+		metadata := NewPackageMetadata(context.Application.Path, execJar)
+
+		web, err := b.isWebApplication(cr, context.Application.Path, metadata)
+		if err != nil {
+			return libcnb.BuildResult{}, err
+		}
 
-			for i := 0; i < len(result.Processes); i++ {
-				result.Processes[i].Default = false
+		defaultType := "executable-jar"
+		if aliases {
+			defaultType = "task"
+			result.Processes = append(result.Processes,
+				libcnb.Process{
+					Type:      "task",
+					Command:   command,
+					Arguments: arguments,
+					Direct:    true,
+				},
+			)
+
+			if web {
+				defaultType = "web"
+				result.Processes = append(result.Processes,
+					libcnb.Process{
+						Type:      "web",
+						Command:   command,
+						Arguments: arguments,
+						Direct:    true,
+					},
+				)
 			}
+		}
 
+		definitions, err := NewProcessDefinitions(cr)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to configure processes\n%w", err)
+		}
+		for _, d := range definitions {
+			result.Processes = append(result.Processes, d.Process(command, arguments))
+		}
+
+		var debug Debug
+		if cr.ResolveBool("BP_EXECUTABLE_JAR_DEBUG_ENABLED") || liveReload {
+			debug, err = NewDebug(cr)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to configure debugging\n%w", err)
+			}
+		}
+
+		if cr.ResolveBool("BP_EXECUTABLE_JAR_DEBUG_ENABLED") {
 			result.Processes = append(result.Processes,
 				libcnb.Process{
-					Type:      "reload",
-					Command:   "watchexec",
-					Arguments: append([]string{"-r", "--shell=none", "--", command}, arguments...),
+					Type:      "debug",
+					Command:   command,
+					Arguments: append([]string{debug.Argument()}, arguments...),
 					Direct:    true,
-					Default:   true,
 				},
 			)
+		}
+
+		if cr.ResolveBool("BP_EXECUTABLE_JAR_PROFILE_ENABLED") {
+			profile, err := NewProfile(cr)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to configure profiling\n%w", err)
+			}
 
-			err = filepath.Walk(context.Application.Path, func(path string, info fs.FileInfo, err error) error {
+			result.Processes = append(result.Processes,
+				libcnb.Process{
+					Type:      "profile",
+					Command:   command,
+					Arguments: append(profile.Arguments(), arguments...),
+					Direct:    true,
+				},
+			)
+		}
+
+		if cr.ResolveBool("BP_EXECUTABLE_JAR_JMX_ENABLED") {
+			jmx, err := NewJMX(cr)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to configure JMX\n%w", err)
+			}
+
+			result.Processes = append(result.Processes,
+				libcnb.Process{
+					Type:      "jmx",
+					Command:   command,
+					Arguments: append(jmx.Arguments(), arguments...),
+					Direct:    true,
+				},
+			)
+		}
+
+		if liveReload {
+			liveReload, err := NewLiveReload(cr)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to configure live reload\n%w", err)
+			}
+
+			watched := cp
+			if !execJar.ExplodedJAR {
+				b.Logger.Body("WARNING: Live Reload has been enabled, however, your command is set to run from a JAR file. This may fail or be a worse experience as the entire JAR file must change to trigger a reload. Set $BP_LIVE_RELOAD_EXPLODE to true to run the JAR exploded.")
+				watched = []string{filepath.Dir(execJar.Path)}
+			}
+
+			watcher, err := resolveWatcher(cr)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to configure live reload\n%w", err)
+			}
+
+			reload := "watchexec"
+			if watcher == WatcherBuiltin {
+				w := NewWatcher(context.Buildpack)
+				w.Logger = b.Logger
+
+				layer, err := context.Layers.Layer(w.Name())
 				if err != nil {
-					return err
-				}
-				if path == context.Application.Path {
-					return nil
+					return libcnb.BuildResult{}, fmt.Errorf("unable to create watcher layer\n%w", err)
 				}
+				reload = w.Command(layer)
 
-				return os.Chmod(path, info.Mode()|0060)
-			})
-			if err != nil {
-				return libcnb.BuildResult{}, fmt.Errorf("unable to mark files as group read-write for live reload\n%w", err)
+				result.Layers = append(result.Layers, w)
+			}
+
+			defaultType = "reload"
+			result.Processes = append(result.Processes,
+				libcnb.Process{
+					Type:      "reload",
+					Command:   reload,
+					Arguments: liveReload.Arguments(context.Application.Path, watched, command, arguments),
+					Direct:    true,
+				},
+			)
+
+			// the agent listens again after each restart, so a debugger that reconnects reattaches to the new JVM
+			result.Processes = append(result.Processes,
+				libcnb.Process{
+					Type:      "reload-debug",
+					Command:   reload,
+					Arguments: liveReload.Arguments(context.Application.Path, watched, command, append([]string{debug.Argument()}, arguments...)),
+					Direct:    true,
+				},
+			)
+
+			if liveReload.Permissions != 0 {
+				summary := AddPermissions(liveReload.Watched(context.Application.Path, watched), liveReload.Permissions, 0)
+				b.Logger.Bodyf("Added %04o permissions for live reload: %s", uint32(liveReload.Permissions), summary)
+				for _, err := range summary.Errors {
+					b.Logger.Debugf("%s", err)
+				}
 			}
 		}
 
-		if b.SBOMScanner == nil {
-			b.SBOMScanner = sbom.NewSyftCLISBOMScanner(context.Layers, effect.CommandExecutor{}, b.Logger)
+		if s, _ := cr.Resolve("BP_EXECUTABLE_JAR_DEFAULT_PROCESS"); s != "" {
+			defaultType = s
 		}
-		if err := b.SBOMScanner.ScanLaunch(context.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON); err != nil {
-			return libcnb.BuildResult{}, fmt.Errorf("unable to create Build SBoM \n%w", err)
+		if err := setDefaultProcess(result.Processes, defaultType); err != nil {
+			return libcnb.BuildResult{}, err
 		}
 	}
 
-	if execJar.ExplodedJAR {
-		cp := []string{context.Application.Path}
-		if s, ok := execJar.Properties.Get("Class-Path"); ok {
-			cp = append(cp, strings.Split(s, " ")...)
+	classPath := RuntimeClassPath(context.Application.Path, execJar)
+
+	signatures, err := LoadClassSignatures(context.Platform.Bindings)
+	if err != nil {
+		return libcnb.BuildResult{}, err
+	}
+
+	inventory, err := inventoryNeeded(context, cr, launch)
+	if err != nil {
+		return libcnb.BuildResult{}, err
+	}
+
+	layer, err := context.Layers.Layer(SBOMCache{}.Name())
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to create SBOM cache layer\n%w", err)
+	}
+	cache := NewPackageCache(layer.Path)
+
+	inspection, err := Inspect(context.Application.Path, classPath, signatures, inventory, cache)
+	if err != nil {
+		return libcnb.BuildResult{}, err
+	}
+
+	if err := checkIntegrity(inspection.Problems); err != nil {
+		return libcnb.BuildResult{}, err
+	}
+
+	if err := b.verifySignatures(context, cr, classPath); err != nil {
+		return libcnb.BuildResult{}, err
+	}
+
+	removed, err := b.checkDangerousClasses(context, cr, inspection.DangerousClasses)
+	if err != nil {
+		return libcnb.BuildResult{}, err
+	}
+
+	packages := inspection.Packages
+	if inventory && len(removed) > 0 {
+		if packages, err = Reinventory(context.Application.Path, packages, removed, cache); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to find libraries\n%w", err)
+		}
+	}
+
+	if err := b.writeSBOM(context, cr, classPath, launch, cache, packages); err != nil {
+		return libcnb.BuildResult{}, err
+	}
+
+	var vulnerabilities []Vulnerability
+	var advisories bool
+	if inventory {
+		if err := checkLicenses(cr, packages); err != nil {
+			return libcnb.BuildResult{}, err
+		}
+
+		if vulnerabilities, advisories, err = b.checkVulnerabilities(context, cr, packages); err != nil {
+			return libcnb.BuildResult{}, err
 		}
+	}
 
+	if execJar.ExplodedJAR {
 		classpathLayer := NewClassPath(cp, launch)
 		classpathLayer.Logger = b.Logger
 		result.Layers = append(result.Layers, classpathLayer)
 	}
 
+	if launch {
+		report := NewLicenseReport(packages)
+		report.Logger = b.Logger
+		result.Layers = append(result.Layers, report)
+	}
+
+	if launch && advisories {
+		report := NewVulnerabilityReport(vulnerabilities, FindUncheckedPackages(packages))
+		report.Logger = b.Logger
+		result.Layers = append(result.Layers, report)
+	}
+
+	if inventory {
+		b.Logger.Bodyf("Scanned %d archives, reused the packages of %d unchanged archives", cache.Misses, cache.Hits)
+	}
+	if err := cache.Prune(); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to prune SBOM cache\n%w", err)
+	}
+	result.Layers = append(result.Layers, SBOMCache{})
+
 	return result, nil
 }
+
+// writeSBOM writes the launch SBOM of the application with the scanner selected by $BP_EXECUTABLE_JAR_SBOM. An
+// executable JAR that is an input to a native image build is not launched, so a build SBOM is written instead to record
+// the libraries compiled into the native image. The native scanner writes the packages already found in the
+// application, and syft keeps the SBOMs of the archives it scans in cache.
+func (b Build) writeSBOM(context libcnb.BuildContext, cr libpak.ConfigurationResolver, classPath []string, launch bool,
+	cache *PackageCache, packages []Package) error {
+	scanner, err := resolveSBOM(cr)
+	if err != nil {
+		return err
+	} else if scanner == SBOMNone {
+		return nil
+	}
+
+	formats, err := resolveSBOMFormats(cr)
+	if err != nil {
+		return err
+	}
+
+	if b.SBOMScanner == nil && scanner == SBOMNative {
+		n := NewNativeSBOMScanner(context.Layers, b.Logger)
+		n.ClassPath = classPath
+		n.Cache = cache
+		n.Packages = packages
+		b.SBOMScanner = n
+	} else if b.SBOMScanner == nil {
+		s := NewSyftSBOMScanner(context.Layers, effect.CommandExecutor{}, b.Logger)
+		if cr.ResolveBool("BP_EXECUTABLE_JAR_SYFT_CACHE_ENABLED") {
+			s.Cache = cache
+		}
+		b.SBOMScanner = s
+		for _, c := range outside(context.Application.Path, classPath) {
+			b.Logger.Bodyf("WARNING: Class-Path entry %s is outside of the application and is not scanned by syft. Set $BP_EXECUTABLE_JAR_SBOM to native to include it in the SBOM.", c)
+		}
+	}
+
+	if !launch {
+		if err := b.SBOMScanner.ScanBuild(context.Application.Path, formats...); err != nil {
+			return fmt.Errorf("unable to create Build SBoM \n%w", err)
+		}
+	} else if err := b.SBOMScanner.ScanLaunch(context.Application.Path, formats...); err != nil {
+		return fmt.Errorf("unable to create Launch SBoM \n%w", err)
+	}
+
+	return nil
+}
+
+// checkIntegrity fails if there are problems, because archives on the classpath are corrupt or have unsafe entries.
+func checkIntegrity(problems []IntegrityProblem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	var s []string
+	for _, p := range problems {
+		s = append(s, p.String())
+	}
+	return fmt.Errorf("found %d problems in the archives on the classpath:\n  %s", len(problems), strings.Join(s, "\n  "))
+}
+
+// verifySignatures verifies the signed JARs on classPath against the signature-trust-store binding if
+// $BP_EXECUTABLE_JAR_VERIFY_SIGNATURES is set, and fails if it is enforce and there is no binding or any signature is
+// not valid. Signatures are verified before dangerous classes are removed, as removing them invalidates the signatures
+// of their archives.
+func (b Build) verifySignatures(context libcnb.BuildContext, cr libpak.ConfigurationResolver, classPath []string) error {
+	mode, err := resolveVerifySignatures(cr)
+	if err != nil {
+		return err
+	} else if mode == "" {
+		return nil
+	}
+
+	binding, ok, err := bindings.ResolveOne(context.Platform.Bindings, bindings.OfType(SignatureTrustStoreBindingType))
+	if err != nil {
+		return fmt.Errorf("unable to resolve %s binding\n%w", SignatureTrustStoreBindingType, err)
+	}
+
+	trustStore := x509.NewCertPool()
+	if ok {
+		if trustStore, err = LoadTrustStore(binding); err != nil {
+			return err
+		}
+	} else if mode == VerifySignaturesEnforce {
+		return fmt.Errorf("$BP_EXECUTABLE_JAR_VERIFY_SIGNATURES is %s but there is no %s binding to verify signers against",
+			VerifySignaturesEnforce, SignatureTrustStoreBindingType)
+	} else {
+		b.Logger.Bodyf("WARNING: No %s binding, the signers of signed JARs are not trusted", SignatureTrustStoreBindingType)
+	}
+
+	signed, err := VerifyJARSignatures(classPath, trustStore)
+	if err != nil {
+		return fmt.Errorf("unable to verify JAR signatures\n%w", err)
+	}
+
+	var invalid []string
+	for _, s := range signed {
+		switch {
+		case s.Valid():
+			b.Logger.Bodyf("Verified %s", s)
+		case mode == VerifySignaturesEnforce:
+			b.Logger.Bodyf("ERROR: %s", s)
+			invalid = append(invalid, s.Location)
+		default:
+			b.Logger.Bodyf("WARNING: %s", s)
+			invalid = append(invalid, s.Location)
+		}
+	}
+	b.Logger.Bodyf("Verified the signatures of %d signed JARs, %d are not valid", len(signed), len(invalid))
+
+	if mode == VerifySignaturesEnforce && len(invalid) > 0 {
+		return fmt.Errorf("%d signed JARs do not have valid signatures:\n  %s", len(invalid), strings.Join(invalid, "\n  "))
+	}
+	return nil
+}
+
+// checkDangerousClasses, depending on $BP_EXECUTABLE_JAR_DANGEROUS_CLASSES, fails because of the dangerous classes
+// found, removes them or warns of them. It returns the locations of the classes it removes.
+func (b Build) checkDangerousClasses(context libcnb.BuildContext, cr libpak.ConfigurationResolver, found []DangerousClass) ([]string, error) {
+	action, err := resolveDangerousClasses(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, d := range found {
+		switch action {
+		case DangerousClassesRemove:
+			if err := RemoveDangerousClass(context.Application.Path, d); err != nil {
+				return nil, err
+			}
+			b.Logger.Bodyf("Removed %s", d)
+			removed = append(removed, d.Location)
+		case DangerousClassesWarn:
+			b.Logger.Bodyf("WARNING: %s", d)
+		default:
+			b.Logger.Bodyf("ERROR: %s", d)
+		}
+	}
+
+	if action == DangerousClassesFail && len(found) > 0 {
+		return nil, fmt.Errorf("found %d dangerous classes, set $BP_EXECUTABLE_JAR_DANGEROUS_CLASSES to remove to remove them", len(found))
+	}
+	return removed, nil
+}
+
+// inventoryNeeded reports whether the libraries of the application are needed: for the native SBOM, the license
+// policy, the license report of an application that is launched or the advisory database binding. Otherwise the
+// packages of the archives are not read.
+func inventoryNeeded(context libcnb.BuildContext, cr libpak.ConfigurationResolver, launch bool) (bool, error) {
+	if launch {
+		return true, nil
+	}
+
+	if scanner, err := resolveSBOM(cr); err != nil {
+		return false, err
+	} else if scanner == SBOMNative {
+		return true, nil
+	}
+
+	if policy, err := NewLicensePolicy(cr); err != nil {
+		return false, err
+	} else if len(policy.Allow) > 0 || len(policy.Deny) > 0 {
+		return true, nil
+	}
+
+	_, ok, err := bindings.ResolveOne(context.Platform.Bindings, bindings.OfType(AdvisoryDatabaseBindingType))
+	if err != nil {
+		return false, fmt.Errorf("unable to resolve %s binding\n%w", AdvisoryDatabaseBindingType, err)
+	}
+	return ok, nil
+}
+
+// checkLicenses fails if any of packages violates the policy of $BP_EXECUTABLE_JAR_LICENSE_ALLOW and
+// $BP_EXECUTABLE_JAR_LICENSE_DENY.
+func checkLicenses(cr libpak.ConfigurationResolver, packages []Package) error {
+	policy, err := NewLicensePolicy(cr)
+	if err != nil {
+		return err
+	}
+
+	violations := policy.Check(packages)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%d libraries violate the license policy of $BP_EXECUTABLE_JAR_LICENSE_ALLOW and $BP_EXECUTABLE_JAR_LICENSE_DENY:\n  %s", len(violations), strings.Join(violations, "\n  "))
+}
+
+// checkVulnerabilities matches packages against the advisory database binding, if there is one, and returns the
+// vulnerabilities it finds. It fails if any is at least as severe as $BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY and
+// warns of the others.
+func (b Build) checkVulnerabilities(context libcnb.BuildContext, cr libpak.ConfigurationResolver, packages []Package) ([]Vulnerability, bool, error) {
+	binding, ok, err := bindings.ResolveOne(context.Platform.Bindings, bindings.OfType(AdvisoryDatabaseBindingType))
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to resolve %s binding\n%w", AdvisoryDatabaseBindingType, err)
+	} else if !ok {
+		return nil, false, nil
+	}
+
+	threshold, err := resolveVulnerabilitySeverity(cr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	advisories, err := LoadAdvisories(binding)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to load advisories from binding %s\n%w", binding.Name, err)
+	}
+
+	vulnerabilities := FindVulnerabilities(advisories, packages)
+	unchecked := FindUncheckedPackages(packages)
+	b.Logger.Bodyf("Checked %d libraries against %d advisories, found %d vulnerabilities",
+		len(packages)-len(unchecked), len(advisories), len(vulnerabilities))
+
+	if len(unchecked) > 0 {
+		b.Logger.Bodyf("WARNING: %d libraries were not checked as their Maven group or version is not known:", len(unchecked))
+		for _, u := range unchecked {
+			b.Logger.Bodyf("  %s", u)
+		}
+	}
+
+	failures := 0
+	for _, v := range vulnerabilities {
+		if threshold != SeverityNone && v.Severity >= threshold {
+			b.Logger.Bodyf("ERROR: %s", v)
+			failures++
+		} else {
+			b.Logger.Bodyf("WARNING: %s", v)
+		}
+	}
+	if failures > 0 {
+		return nil, true, fmt.Errorf("found %d vulnerabilities at least as severe as $BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY %s", failures, threshold)
+	}
+
+	return vulnerabilities, true, nil
+}
+
+// isWebApplication reports whether the web process should be contributed, either because it is configured to or
+// because a server is found on the classpath.
+func (b Build) isWebApplication(cr libpak.ConfigurationResolver, appPath string, metadata PackageMetadata) (bool, error) {
+	applicationType, err := ResolveApplicationType(cr)
+	if err != nil {
+		return false, err
+	}
+
+	if applicationType != ApplicationTypeAuto {
+		b.Logger.Bodyf("$BP_EXECUTABLE_JAR_APPLICATION_TYPE is %s", applicationType)
+		return applicationType == ApplicationTypeWeb, nil
+	}
+
+	stack, err := FindServerStack(os.DirFS(appPath), metadata)
+	if err != nil {
+		return false, fmt.Errorf("unable to find server\n%w", err)
+	}
+
+	if stack == "" {
+		b.Logger.Body("No server found on the classpath, contributing task rather than web as the default process")
+		b.Logger.Body("WARNING: if the application is a web application, set $BP_EXECUTABLE_JAR_APPLICATION_TYPE=web to contribute web as the default process")
+		return false, nil
+	}
+
+	b.Logger.Bodyf("%s found on the classpath, contributing web as the default process", stack)
+	return true, nil
+}
+
+// setDefaultProcess marks the process of type defaultType as the default, checking that there is one and that no two
+// processes share a type.
+func setDefaultProcess(processes []libcnb.Process, defaultType string) error {
+	found := false
+	types := map[string]bool{}
+
+	for i, p := range processes {
+		if types[p.Type] {
+			return fmt.Errorf("process type %s is contributed more than once", p.Type)
+		}
+		types[p.Type] = true
+
+		processes[i].Default = p.Type == defaultType
+		found = found || processes[i].Default
+	}
+
+	if !found {
+		return fmt.Errorf("default process type %s is not contributed", defaultType)
+	}
+
+	return nil
+}
@@ -17,12 +17,16 @@
 package executable_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/paketo-buildpacks/libpak/sbom/mocks"
 
 	"github.com/buildpacks/libcnb"
@@ -46,7 +50,10 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "META-INF"), 0755)).To(Succeed())
 		sbomScanner = mocks.SBOMScanner{}
 		sbomScanner.On("ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON).Return(nil)
+		sbomScanner.On("ScanBuild", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON).Return(nil)
 
+		// the test applications have no server on their classpath
+		t.Setenv("BP_EXECUTABLE_JAR_APPLICATION_TYPE", "web")
 	})
 
 	it.After(func() {
@@ -183,13 +190,592 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
 		})
 
-		context("$BP_LIVE_RELOAD_ENABLED is true", func() {
+		it("uses the configuration in executable-jar.toml", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "executable-jar.toml"), []byte(`
+jvm-options = ["-Xmx1g"]
+aliases     = false
+
+[processes.worker]
+args = ["--queue", "jobs"]
+`), 0644)).To(Succeed())
+
+			result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Processes).To(ConsistOf(
+				libcnb.Process{Type: "executable-jar", Command: "java", Arguments: []string{"-Xmx1g", "test-main-class"}, Direct: true, Default: true},
+				libcnb.Process{Type: "worker", Command: "java", Arguments: []string{"-Xmx1g", "test-main-class", "--queue", "jobs"}, Direct: true},
+			))
+		})
+
+		it("returns an error for an invalid executable-jar.toml", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "executable-jar.toml"), []byte("aliases = \"no\"\n"), 0644)).To(Succeed())
+
+			_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to parse executable-jar.toml")))
+		})
+
+		context("$BP_EXECUTABLE_JAR_PROCESSES is set", func() {
+			it.Before(func() {
+				t.Setenv("BP_EXECUTABLE_JAR_PROCESSES", "worker")
+				t.Setenv("BP_EXECUTABLE_JAR_PROCESS_WORKER_JVM_OPTIONS", "-Xmx1g")
+				t.Setenv("BP_EXECUTABLE_JAR_PROCESS_WORKER_ARGS", "--queue jobs")
+			})
+
+			it("contributes the declared processes", func() {
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Processes).To(ConsistOf(
+					libcnb.Process{Type: "executable-jar", Command: "java", Arguments: []string{"test-main-class"}, Direct: true},
+					libcnb.Process{Type: "task", Command: "java", Arguments: []string{"test-main-class"}, Direct: true},
+					libcnb.Process{Type: "web", Command: "java", Arguments: []string{"test-main-class"}, Direct: true, Default: true},
+					libcnb.Process{Type: "worker", Command: "java", Arguments: []string{"-Xmx1g", "test-main-class", "--queue", "jobs"}, Direct: true},
+				))
+			})
+
+			it("marks the configured default process", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_DEFAULT_PROCESS", "worker")
+				t.Setenv("BP_EXECUTABLE_JAR_ALIASES_ENABLED", "false")
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Processes).To(ConsistOf(
+					libcnb.Process{Type: "executable-jar", Command: "java", Arguments: []string{"test-main-class"}, Direct: true},
+					libcnb.Process{Type: "worker", Command: "java", Arguments: []string{"-Xmx1g", "test-main-class", "--queue", "jobs"}, Direct: true, Default: true},
+				))
+			})
+
+			it("fails if the default process is not contributed", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_DEFAULT_PROCESS", "admin")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError("default process type admin is not contributed"))
+			})
+
+			it("fails if a declared process replaces a built-in one", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_PROCESSES", "web")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError("process type web is contributed more than once"))
+			})
+		})
+
+		it("makes executable-jar the default without aliases", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_ALIASES_ENABLED", "false")
+
+			result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Processes).To(Equal([]libcnb.Process{
+				{Type: "executable-jar", Command: "java", Arguments: []string{"test-main-class"}, Direct: true, Default: true},
+			}))
+		})
+
+		context("$BP_EXECUTABLE_JAR_APPLICATION_TYPE is auto", func() {
+			it.Before(func() {
+				t.Setenv("BP_EXECUTABLE_JAR_APPLICATION_TYPE", "auto")
+			})
+
+			it("makes task the default without a server", func() {
+				out := &bytes.Buffer{}
+				result, err := executable.Build{Logger: bard.NewLogger(out), SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(out.String()).To(ContainSubstring("WARNING: if the application is a web application, set $BP_EXECUTABLE_JAR_APPLICATION_TYPE=web"))
+
+				Expect(result.Processes).To(Equal([]libcnb.Process{
+					{Type: "executable-jar", Command: "java", Arguments: []string{"test-main-class"}, Direct: true},
+					{Type: "task", Command: "java", Arguments: []string{"test-main-class"}, Direct: true, Default: true},
+				}))
+			})
+
+			it("makes web the default with a server", func() {
+				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "jetty-server-11.0.15.jar"), map[string]string{})
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Processes).To(Equal([]libcnb.Process{
+					{Type: "executable-jar", Command: "java", Arguments: []string{"test-main-class"}, Direct: true},
+					{Type: "task", Command: "java", Arguments: []string{"test-main-class"}, Direct: true},
+					{Type: "web", Command: "java", Arguments: []string{"test-main-class"}, Direct: true, Default: true},
+				}))
+			})
+		})
+
+		it("does not contribute web if $BP_EXECUTABLE_JAR_APPLICATION_TYPE is cli", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_APPLICATION_TYPE", "cli")
+
+			result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Processes).NotTo(ContainElement(HaveField("Type", "web")))
+			Expect(result.Processes).To(ContainElement(HaveField("Type", "task")))
+		})
+
+		it("returns an error for an invalid $BP_EXECUTABLE_JAR_APPLICATION_TYPE", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_APPLICATION_TYPE", "desktop")
+
+			_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("BP_EXECUTABLE_JAR_APPLICATION_TYPE")))
+		})
+
+		context("$BP_EXECUTABLE_JAR_SBOM is set", func() {
+			it("writes the launch SBOM with the native scanner", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_SBOM", "native")
+
+				result, err := executable.Build{}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ctx.Layers.LaunchSBOMPath(libcnb.SyftJSON)).To(BeARegularFile())
+				Expect(ctx.Layers.LaunchSBOMPath(libcnb.CycloneDXJSON)).To(BeARegularFile())
+				Expect(result.Layers).To(ContainElement(executable.SBOMCache{}))
+			})
+
+			it("writes the formats in $BP_EXECUTABLE_JAR_SBOM_FORMATS", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_SBOM", "native")
+				t.Setenv("BP_EXECUTABLE_JAR_SBOM_FORMATS", "spdx, cyclonedx")
+
+				_, err := executable.Build{}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ctx.Layers.LaunchSBOMPath(libcnb.SPDXJSON)).To(BeARegularFile())
+				Expect(ctx.Layers.LaunchSBOMPath(libcnb.CycloneDXJSON)).To(BeARegularFile())
+				Expect(ctx.Layers.LaunchSBOMPath(libcnb.SyftJSON)).NotTo(BeAnExistingFile())
+			})
+
+			it("reads each archive once for the native SBOM and the libraries", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_SBOM", "native")
+				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "plain-1.0.jar"), map[string]string{})
+
+				out := &bytes.Buffer{}
+				_, err := executable.Build{Logger: bard.NewLogger(out)}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(out.String()).To(ContainSubstring("Scanned 1 archives, reused the packages of 0 unchanged archives"))
+
+				out.Reset()
+				_, err = executable.Build{Logger: bard.NewLogger(out)}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(out.String()).To(ContainSubstring("Scanned 0 archives, reused the packages of 1 unchanged archives"))
+			})
+
+			it("returns an error for an unknown format", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_SBOM_FORMATS", "swid")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_SBOM_FORMATS must be")))
+			})
+
+			it("does not write a launch SBOM if it is none", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_SBOM", "none")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				sbomScanner.AssertNotCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
+			})
+		})
+
+		context("license policy", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "gpl-1.0.jar"), map[string]string{
+					"META-INF/MANIFEST.MF": "Bundle-License: GPL-3.0-only\n",
+				})
+			})
+
+			it("contributes the license report", func() {
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(ContainElement(WithTransform(func(l libcnb.LayerContributor) []executable.Package {
+					r, _ := l.(executable.LicenseReport)
+					return r.Packages
+				}, ConsistOf(And(
+					HaveField("Location", "lib/gpl-1.0.jar"),
+					HaveField("Licenses", []string{"GPL-3.0-only"}),
+				)))))
+			})
+
+			it("fails with libraries whose licenses are denied", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_LICENSE_DENY", "GPL-*")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("pkg:maven/gpl@1.0 at lib/gpl-1.0.jar is licensed under GPL-3.0-only")))
+			})
+		})
+
+		context("dangerous classes", func() {
 			it.Before(func() {
-				Expect(os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "lib", "log4j-core-2.14.1.jar"), []byte(log4jCore(t, "2.14.1")), 0644)).To(Succeed())
 			})
 
-			it.After(func() {
-				Expect(os.Unsetenv("BP_LIVE_RELOAD_ENABLED")).To(Succeed())
+			it("fails", func() {
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("found 1 dangerous classes")))
+			})
+
+			it("removes them", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_DANGEROUS_CLASSES", "remove")
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				z, err := zip.OpenReader(filepath.Join(ctx.Application.Path, "lib", "log4j-core-2.14.1.jar"))
+				Expect(err).NotTo(HaveOccurred())
+				defer z.Close()
+				Expect(z.File).NotTo(ContainElement(HaveField("Name", jndiLookup)))
+
+				b, err := os.ReadFile(filepath.Join(ctx.Application.Path, "lib", "log4j-core-2.14.1.jar"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Layers).To(ContainElement(WithTransform(func(l libcnb.LayerContributor) []executable.Package {
+					r, _ := l.(executable.LicenseReport)
+					return r.Packages
+				}, ContainElement(HaveField("SHA256", fmt.Sprintf("%x", sha256.Sum256(b)))))))
+			})
+
+			it("warns of them", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_DANGEROUS_CLASSES", "warn")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("returns an error for an invalid $BP_EXECUTABLE_JAR_DANGEROUS_CLASSES", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_DANGEROUS_CLASSES", "ignore")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_DANGEROUS_CLASSES must be fail, remove or warn")))
+			})
+		})
+
+		context("archive integrity", func() {
+			it("fails with the offending entries of corrupt Class-Path archives", func() {
+				Expect(os.WriteFile(
+					filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+					[]byte("Main-Class: test-main-class\nClass-Path: lib/corrupt.jar"),
+					0644,
+				)).To(Succeed())
+
+				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+				file := filepath.Join(ctx.Application.Path, "lib", "corrupt.jar")
+				b := createStoredZIP(t, file, [][2]string{{"com/example/Library.class", "original"}})
+				Expect(os.WriteFile(file, bytes.Replace(b, []byte("original"), []byte("modified"), 1), 0644)).To(Succeed())
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(fmt.Sprintf("found 1 problems in the archives on the classpath:\n  %s: com/example/Library.class: cannot be read: zip: checksum error",
+					filepath.ToSlash(file))))
+			})
+		})
+
+		context("signature verification", func() {
+			var signer jarSigner
+
+			it.Before(func() {
+				signer = newJARSigner(t, "Example Signer")
+
+				entries := signJAR(t, map[string]string{"com/example/Library.class": "library"}, signer, true)
+				entries["com/example/Library.class"] = "tampered"
+
+				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "signed.jar"), entries)
+
+				ctx.Platform.Bindings = libcnb.Bindings{
+					libcnb.NewBinding("trust", t.TempDir(), map[string]string{
+						"type":   executable.SignatureTrustStoreBindingType,
+						"ca.pem": signer.PEM(),
+					}),
+				}
+			})
+
+			it("does not verify signatures by default", func() {
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("fails with invalid signatures if enforced", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_VERIFY_SIGNATURES", "enforce")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(fmt.Sprintf("1 signed JARs do not have valid signatures:\n  %s",
+					filepath.ToSlash(filepath.Join(ctx.Application.Path, "lib", "signed.jar")))))
+			})
+
+			it("warns of invalid signatures", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_VERIFY_SIGNATURES", "warn")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("accepts valid signatures of trusted signers", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_VERIFY_SIGNATURES", "enforce")
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "signed.jar"),
+					signJAR(t, map[string]string{"com/example/Library.class": "library"}, signer, true))
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("does not trust any signer without a binding", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_VERIFY_SIGNATURES", "warn")
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "signed.jar"),
+					signJAR(t, map[string]string{"com/example/Library.class": "library"}, signer, true))
+				ctx.Platform.Bindings = nil
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("returns an error if enforced without a binding", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_VERIFY_SIGNATURES", "enforce")
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "signed.jar"),
+					signJAR(t, map[string]string{"com/example/Library.class": "library"}, signer, true))
+				ctx.Platform.Bindings = nil
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError("$BP_EXECUTABLE_JAR_VERIFY_SIGNATURES is enforce but there is no signature-trust-store binding to verify signers against"))
+			})
+
+			it("returns an error for an invalid $BP_EXECUTABLE_JAR_VERIFY_SIGNATURES", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_VERIFY_SIGNATURES", "strict")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError("$BP_EXECUTABLE_JAR_VERIFY_SIGNATURES must be warn or enforce, not \"strict\""))
+			})
+		})
+
+		context("advisory database binding", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "log4j-core-2.14.1.jar"), map[string]string{
+					"META-INF/maven/org.apache.logging.log4j/log4j-core/pom.properties": "groupId=org.apache.logging.log4j\nartifactId=log4j-core\nversion=2.14.1\n",
+				})
+
+				ctx.Platform.Bindings = libcnb.Bindings{
+					libcnb.NewBinding("advisories", t.TempDir(), map[string]string{
+						"type":       executable.AdvisoryDatabaseBindingType,
+						"log4j.json": log4Shell,
+					}),
+				}
+			})
+
+			it("fails with vulnerabilities at least as severe as the threshold", func() {
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError("found 1 vulnerabilities at least as severe as $BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY high"))
+			})
+
+			it("warns of less severe vulnerabilities and contributes the vulnerability report", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY", "none")
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(ContainElement(WithTransform(func(l libcnb.LayerContributor) []executable.Vulnerability {
+					r, _ := l.(executable.VulnerabilityReport)
+					return r.Vulnerabilities
+				}, ConsistOf(HaveField("ID", "GHSA-jfh8-c2jp-5v3q")))))
+			})
+
+			it("logs and reports the libraries that cannot be checked", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY", "none")
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "renamed.jar"), map[string]string{
+					"com/example/Library.class": "library",
+				})
+				out := &bytes.Buffer{}
+
+				result, err := executable.Build{Logger: bard.NewLogger(out), SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(out.String()).To(ContainSubstring("WARNING: 1 libraries were not checked as their Maven group or version is not known"))
+				Expect(out.String()).To(ContainSubstring("renamed at lib/renamed.jar"))
+				Expect(result.Layers).To(ContainElement(WithTransform(func(l libcnb.LayerContributor) []executable.UncheckedPackage {
+					r, _ := l.(executable.VulnerabilityReport)
+					return r.Unchecked
+				}, ConsistOf(executable.UncheckedPackage{Name: "renamed", Location: "lib/renamed.jar"}))))
+			})
+
+			it("returns an error for an invalid $BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY", "severe")
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY must be")))
+			})
+		})
+
+		it("ignores the debug configuration if debugging is not enabled", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_DEBUG_PORT", "debug")
+			Expect(os.WriteFile(
+				filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+				[]byte(`Main-Class: test-main-class`),
+				0644,
+			)).To(Succeed())
+
+			result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Processes).NotTo(ContainElement(HaveField("Type", "debug")))
+		})
+
+		it("returns an error for an invalid debug configuration if debugging is enabled", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_DEBUG_ENABLED", "true")
+			t.Setenv("BP_EXECUTABLE_JAR_DEBUG_PORT", "debug")
+			Expect(os.WriteFile(
+				filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+				[]byte(`Main-Class: test-main-class`),
+				0644,
+			)).To(Succeed())
+
+			_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_DEBUG_PORT must be a port number")))
+		})
+
+		context("$BP_EXECUTABLE_JAR_DEBUG_ENABLED is true", func() {
+			it.Before(func() {
+				t.Setenv("BP_EXECUTABLE_JAR_DEBUG_ENABLED", "true")
+			})
+
+			it("contributes debug process type for an exploded JAR", func() {
+				Expect(os.WriteFile(
+					filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+					[]byte(`Main-Class: test-main-class`),
+					0644,
+				)).To(Succeed())
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Processes).To(ContainElement(libcnb.Process{
+					Type:      "debug",
+					Command:   "java",
+					Arguments: []string{"-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:8000", "test-main-class"},
+					Direct:    true,
+				}))
+				Expect(result.Processes).To(ContainElement(libcnb.Process{
+					Type:      "web",
+					Command:   "java",
+					Arguments: []string{"test-main-class"},
+					Direct:    true,
+					Default:   true,
+				}))
+			})
+
+			it("uses the wildcard address for an application compiled for Java 8 on a later JVM", func() {
+				Expect(os.WriteFile(
+					filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+					[]byte("Main-Class: test-main-class\nBuild-Jdk-Spec: 1.8\n"),
+					0644,
+				)).To(Succeed())
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Processes).To(ContainElement(HaveField("Arguments",
+					ContainElement("-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:8000"))))
+			})
+
+			it("contributes debug process type for a JAR", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_DEBUG_SUSPEND", "true")
+
+				Expect(os.RemoveAll(filepath.Join(ctx.Application.Path, "META-INF"))).To(Succeed())
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "app.jar"), map[string]string{
+					"META-INF/MANIFEST.MF": "Main-Class: test-main-class\n",
+				})
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Processes).To(ContainElement(libcnb.Process{
+					Type:      "debug",
+					Command:   "java",
+					Arguments: []string{"-agentlib:jdwp=transport=dt_socket,server=y,suspend=y,address=*:8000", "-jar", filepath.Join(ctx.Application.Path, "app.jar")},
+					Direct:    true,
+				}))
+			})
+		})
+
+		context("diagnostics processes are enabled", func() {
+			it.Before(func() {
+				t.Setenv("BP_EXECUTABLE_JAR_PROFILE_ENABLED", "true")
+				t.Setenv("BP_EXECUTABLE_JAR_JMX_ENABLED", "true")
+			})
+
+			it("contributes profile and jmx process types for an exploded JAR", func() {
+				Expect(os.WriteFile(
+					filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+					[]byte(`Main-Class: test-main-class`),
+					0644,
+				)).To(Succeed())
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Processes).To(ContainElements(
+					libcnb.Process{
+						Type:      "profile",
+						Command:   "java",
+						Arguments: []string{"-XX:StartFlightRecording=dumponexit=true,filename=/tmp/recording.jfr,settings=profile", "test-main-class"},
+						Direct:    true,
+					},
+					libcnb.Process{
+						Type:    "jmx",
+						Command: "java",
+						Arguments: []string{
+							"-Djava.rmi.server.hostname=127.0.0.1",
+							"-Dcom.sun.management.jmxremote.host=127.0.0.1",
+							"-Dcom.sun.management.jmxremote.authenticate=false",
+							"-Dcom.sun.management.jmxremote.ssl=false",
+							"-Dcom.sun.management.jmxremote.port=5000",
+							"-Dcom.sun.management.jmxremote.rmi.port=5000",
+							"test-main-class",
+						},
+						Direct: true,
+					},
+				))
+			})
+
+			it("contributes profile and jmx process types for a JAR", func() {
+				Expect(os.RemoveAll(filepath.Join(ctx.Application.Path, "META-INF"))).To(Succeed())
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "app.jar"), map[string]string{
+					"META-INF/MANIFEST.MF": "Main-Class: test-main-class\n",
+				})
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				jar := filepath.Join(ctx.Application.Path, "app.jar")
+				Expect(result.Processes).To(ContainElement(libcnb.Process{
+					Type:      "profile",
+					Command:   "java",
+					Arguments: []string{"-XX:StartFlightRecording=dumponexit=true,filename=/tmp/recording.jfr,settings=profile", "-jar", jar},
+					Direct:    true,
+				}))
+				Expect(result.Processes).To(ContainElement(SatisfyAll(
+					HaveField("Type", "jmx"),
+					HaveField("Arguments", ContainElements("-Dcom.sun.management.jmxremote.port=5000", "-jar", jar)),
+				)))
+			})
+
+			it("returns an error for invalid configuration", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_PROFILE_DURATION", "forever")
+
+				Expect(os.WriteFile(
+					filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+					[]byte(`Main-Class: test-main-class`),
+					0644,
+				)).To(Succeed())
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("unable to configure profiling")))
+			})
+		})
+
+		context("$BP_LIVE_RELOAD_ENABLED is true", func() {
+			it.Before(func() {
+				t.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
 			})
 
 			it("contributes reloadable process type", func() {
@@ -226,14 +812,101 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 					libcnb.Process{
 						Type:      "reload",
 						Command:   "watchexec",
-						Arguments: []string{"-r", "--shell=none", "--", "java", "test-main-class"},
+						Arguments: []string{"-r", "--shell=none", "--watch=" + ctx.Application.Path, "--", "java", "test-main-class"},
 						Direct:    true,
 						Default:   true,
 					},
+					libcnb.Process{
+						Type:    "reload-debug",
+						Command: "watchexec",
+						Arguments: []string{"-r", "--shell=none", "--watch=" + ctx.Application.Path, "--",
+							"java", "-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:8000", "test-main-class"},
+						Direct: true,
+					},
 				))
 				sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
 			})
 
+			it("explodes the JAR", func() {
+				t.Setenv("BP_LIVE_RELOAD_EXPLODE", "true")
+
+				Expect(os.RemoveAll(filepath.Join(ctx.Application.Path, "META-INF"))).To(Succeed())
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "app.jar"), map[string]string{
+					"META-INF/MANIFEST.MF": "Main-Class: test-main-class\nClass-Path: lib/a.jar\n",
+					"test/A.class":         "test-class",
+				})
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(ctx.Application.Path, "app.jar")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(ctx.Application.Path, "test", "A.class")).To(BeARegularFile())
+				Expect(result.Layers[0].(executable.ClassPath).ClassPath).To(Equal([]string{ctx.Application.Path, "lib/a.jar"}))
+				Expect(result.Processes).To(ContainElement(libcnb.Process{
+					Type:      "reload",
+					Command:   "watchexec",
+					Arguments: []string{"-r", "--shell=none", "--watch=" + ctx.Application.Path, "--", "java", "test-main-class"},
+					Direct:    true,
+					Default:   true,
+				}))
+			})
+
+			it("configures the reload-debug agent", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_DEBUG_PORT", "5005")
+
+				Expect(os.WriteFile(
+					filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+					[]byte(`Main-Class: test-main-class`),
+					0644,
+				)).To(Succeed())
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Processes).To(ContainElement(libcnb.Process{
+					Type:    "reload-debug",
+					Command: "watchexec",
+					Arguments: []string{"-r", "--shell=none", "--watch=" + ctx.Application.Path, "--",
+						"java", "-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:5005", "test-main-class"},
+					Direct: true,
+				}))
+			})
+
+			it("contributes the built-in watcher", func() {
+				t.Setenv("BP_LIVE_RELOAD_WATCHER", "builtin")
+
+				Expect(os.WriteFile(
+					filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+					[]byte(`Main-Class: test-main-class`),
+					0644,
+				)).To(Succeed())
+
+				result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers[0].Name()).To(Equal("watcher"))
+				Expect(result.Processes).To(ContainElement(libcnb.Process{
+					Type:      "reload",
+					Command:   filepath.Join(ctx.Layers.Path, "watcher", "bin", "watcher"),
+					Arguments: []string{"-r", "--shell=none", "--watch=" + ctx.Application.Path, "--", "java", "test-main-class"},
+					Direct:    true,
+					Default:   true,
+				}))
+			})
+
+			it("returns an error for an unknown watcher", func() {
+				t.Setenv("BP_LIVE_RELOAD_WATCHER", "inotifywait")
+
+				Expect(os.WriteFile(
+					filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+					[]byte(`Main-Class: test-main-class`),
+					0644,
+				)).To(Succeed())
+
+				_, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("$BP_LIVE_RELOAD_WATCHER must be watchexec or builtin")))
+			})
+
 			it("marks all workspace files as group read-writable", func() {
 				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "META-INF"), 0755)).To(Succeed())
 				Expect(os.WriteFile(
@@ -287,10 +960,49 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(result.Processes).To(BeEmpty())
-				Expect(result.Layers).To(HaveLen(1))
+				Expect(result.Layers).To(HaveLen(2))
 				Expect(result.Layers[0].(executable.ClassPath).ClassPath).To(Equal([]string{ctx.Application.Path, "test-class-path"}))
 				Expect(result.Layers[0].(executable.ClassPath).Launch).To(BeFalse())
+				Expect(result.Layers[1]).To(Equal(executable.SBOMCache{}))
 				sbomScanner.AssertNotCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
+				sbomScanner.AssertCalled(t, "ScanBuild", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
+			})
+
+			it("does not find the libraries without a license policy", func() {
+				Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+				CreateZIP(t, filepath.Join(ctx.Application.Path, "lib", "plain-1.0.jar"), map[string]string{})
+
+				ctx.Plan.Entries = append(ctx.Plan.Entries, libcnb.BuildpackPlanEntry{
+					Name:     "jvm-application",
+					Metadata: map[string]interface{}{"native-image": true},
+				})
+
+				out := &bytes.Buffer{}
+				_, err := executable.Build{Logger: bard.NewLogger(out), SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(out.String()).NotTo(ContainSubstring("Scanned"))
+
+				t.Setenv("BP_EXECUTABLE_JAR_LICENSE_DENY", "GPL-*")
+
+				out.Reset()
+				_, err = executable.Build{Logger: bard.NewLogger(out), SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(out.String()).To(ContainSubstring("Scanned 1 archives, reused the packages of 0 unchanged archives"))
+			})
+
+			it("writes a native build SBOM of the libraries compiled into the native image", func() {
+				t.Setenv("BP_EXECUTABLE_JAR_SBOM", "native")
+
+				ctx.Plan.Entries = append(ctx.Plan.Entries, libcnb.BuildpackPlanEntry{
+					Name:     "jvm-application",
+					Metadata: map[string]interface{}{"native-image": true},
+				})
+
+				_, err := executable.Build{}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(ctx.Layers.BuildSBOMPath(libcnb.CycloneDXJSON)).To(BeARegularFile())
+				Expect(ctx.Layers.LaunchSBOMPath(libcnb.CycloneDXJSON)).NotTo(BeAnExistingFile())
 			})
 		})
 	})
@@ -328,7 +1040,9 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(len(result.Layers)).To(Equal(0))
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(result.Layers[0].Name()).To(Equal("licenses"))
+			Expect(result.Layers[1]).To(Equal(executable.SBOMCache{}))
 			Expect(result.Processes).To(ContainElements(
 				libcnb.Process{
 					Type:      "executable-jar",
@@ -354,6 +1068,28 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("$BP_EXECUTABLE_JAR_MAIN_CLASS is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_EXECUTABLE_JAR_MAIN_CLASS", "test.Main")
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+			Expect(CreateJAR(filepath.Join(ctx.Application.Path, "lib", "a.jar"), map[string]string{})).To(Succeed())
+		})
+
+		it("contributes process types running the main class with a classpath layer", func() {
+			result, err := executable.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[0].(executable.ClassPath).ClassPath).To(Equal([]string{ctx.Application.Path, "lib/a.jar"}))
+			Expect(result.Processes).To(ContainElement(libcnb.Process{
+				Type:      "web",
+				Command:   "java",
+				Arguments: []string{"test.Main"},
+				Direct:    true,
+				Default:   true,
+			}))
+		})
+	})
+
 	context("JAR files without a Main-Class", func() {
 		it.Before(func() {
 			Expect(CreateJAR(filepath.Join(ctx.Application.Path, "a.jar"), map[string]string{})).To(Succeed())
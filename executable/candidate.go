@@ -0,0 +1,206 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/magiconair/properties"
+)
+
+var (
+	// ErrNoExecutableJAR is returned when neither an exploded JAR nor a JAR file with a Main-Class can be found.
+	ErrNoExecutableJAR = errors.New("no executable JAR found")
+
+	// ErrAmbiguousJAR is returned when a configured location matches more than one executable JAR and
+	// LocatorContext.StrictLocation is set.
+	ErrAmbiguousJAR = errors.New("more than one executable JAR found")
+
+	// ErrInvalidManifest is wrapped by errors for manifests, or the archives containing them, that cannot be parsed.
+	ErrInvalidManifest = errors.New("invalid manifest")
+
+	// ErrNoMainClass is the rejection reason of candidates whose manifest has no Main-Class.
+	ErrNoMainClass = errors.New("no Main-Class manifest attribute")
+)
+
+// Candidate is a possible executable JAR, either the exploded JAR at the root of the application or a JAR file within
+// it.
+type Candidate struct {
+	// Path is the slash-separated path of the candidate relative to the searched fs.FS, "." for an exploded JAR.
+	Path string
+
+	// ExplodedJAR is true if the candidate is the exploded JAR at the root of the application.
+	ExplodedJAR bool
+
+	// Matched is true if the candidate matched the configured executable JAR glob.
+	Matched bool
+
+	// Locator is the name of the Locator that found the candidate.
+	Locator string
+
+	// MainClass is the Main-Class manifest attribute, empty if there is none.
+	MainClass string
+
+	// Properties is the manifest of the candidate, nil if it could not be read.
+	Properties *properties.Properties
+
+	// Rejection is nil if the candidate is executable, otherwise it is the reason the candidate was rejected. It wraps
+	// ErrNoMainClass or ErrInvalidManifest.
+	Rejection error
+}
+
+// ExecutableJAR converts the candidate into an ExecutableJAR.
+func (c Candidate) ExecutableJAR() ExecutableJAR {
+	return ExecutableJAR{
+		MainClass:   c.MainClass,
+		Path:        c.Path,
+		Properties:  c.Properties,
+		Executable:  c.Rejection == nil,
+		ExplodedJAR: c.ExplodedJAR,
+		Locator:     c.Locator,
+	}
+}
+
+// Candidates returns every possible executable JAR in fsys found by the DefaultLocators, both accepted and rejected,
+// ranked in the order in which LoadExecutableJARFS considers them. By default, if the root of fsys contains a
+// META-INF/MANIFEST.MF, the exploded JAR is the only candidate. Otherwise JAR files matching executableJarGlob come
+// first, followed by every other JAR file in breadth-first order. Errors reading the file system are returned,
+// problems with individual candidates are recorded in their Rejection.
+func Candidates(fsys fs.FS, executableJarGlob string) ([]Candidate, error) {
+	return FindCandidates(LocatorContext{FS: fsys, ExecutableJARGlob: executableJarGlob}, DefaultLocators())
+}
+
+// FindCandidates returns every candidate found by locators, in order.
+func FindCandidates(context LocatorContext, locators []Locator) ([]Candidate, error) {
+	var candidates []Candidate
+
+	if err := locate(context, locators, func(c Candidate) bool {
+		candidates = append(candidates, c)
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// FindExecutableJAR returns the first executable candidate found by locators.
+//
+// If nothing executable is found the error wraps ErrNoExecutableJAR. If a candidate ranked ahead of the first
+// executable one has a manifest that cannot be read, the error wraps ErrInvalidManifest. If the executable JAR glob
+// matches more than one executable JAR, the first match is returned and the others are logged as a warning, or the
+// error wraps ErrAmbiguousJAR if context.StrictLocation is set.
+func FindExecutableJAR(context LocatorContext, locators []Locator) (ExecutableJAR, error) {
+	var selected *Candidate
+	var rejected error
+
+	err := locate(context, locators, func(c Candidate) bool {
+		if selected != nil {
+			// keep looking through the glob matches for a second executable JAR
+			if c.Matched && c.Rejection == nil && context.StrictLocation {
+				rejected = fmt.Errorf("%s and %s both match %q\n%w", selected.Path, c.Path, context.ExecutableJARGlob, ErrAmbiguousJAR)
+			} else if c.Matched && c.Rejection == nil {
+				context.Logger.Bodyf("WARNING: %s and %s both match $BP_EXECUTABLE_JAR_LOCATION %q, using %s",
+					selected.Path, c.Path, context.ExecutableJARGlob, selected.Path)
+			}
+			return c.Matched && rejected == nil
+		}
+
+		if errors.Is(c.Rejection, ErrInvalidManifest) {
+			rejected = fmt.Errorf("unable to parse manifest\n%w", c.Rejection)
+			return false
+		}
+
+		if c.Rejection == nil {
+			selected = &c
+		}
+		return true
+	})
+	if err != nil {
+		return ExecutableJAR{}, fmt.Errorf("unable to find executable JAR\n%w", err)
+	}
+	if rejected != nil {
+		return ExecutableJAR{}, rejected
+	}
+	if selected == nil {
+		return ExecutableJAR{}, ErrNoExecutableJAR
+	}
+
+	return selected.ExecutableJAR(), nil
+}
+
+// locate runs each of the locators in turn, passing their candidates to fn until it returns false. Candidates with a
+// path and main class that have already been seen, and excluded JAR files, are skipped. The main class tells apart
+// exploded JARs at the same path, such as the distributions at the application root.
+func locate(context LocatorContext, locators []Locator, fn func(Candidate) bool) error {
+	seen := map[[2]string]bool{}
+	stopped := false
+
+	for _, l := range locators {
+		err := l.Locate(context, func(c Candidate) bool {
+			key := [2]string{c.Path, c.MainClass}
+			if seen[key] || (!c.ExplodedJAR && context.excluded(c.Path)) {
+				return true
+			}
+			seen[key] = true
+
+			c.Locator = l.Name()
+			stopped = !fn(c)
+			return !stopped
+		})
+		if errors.Is(err, ErrSkipLocators) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("unable to locate executable JAR with %s locator\n%w", l.Name(), err)
+		}
+
+		if stopped {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// newCandidate loads the manifest of a candidate. Invalid manifests are recorded as the rejection reason, any other
+// error is returned.
+func newCandidate(name string, exploded bool, matched bool, manifest func() (*properties.Properties, error)) (Candidate, error) {
+	c := Candidate{Path: name, ExplodedJAR: exploded, Matched: matched}
+
+	props, err := manifest()
+	if errors.Is(err, ErrInvalidManifest) {
+		c.Rejection = err
+		return c, nil
+	} else if err != nil {
+		return Candidate{}, err
+	}
+
+	c.Properties = props
+	if mc, ok := props.Get("Main-Class"); ok {
+		c.MainClass = mc
+	} else {
+		c.Rejection = ErrNoMainClass
+	}
+
+	return c, nil
+}
+
+func invalidManifest(err error) error {
+	return fmt.Errorf("%w\n%w", ErrInvalidManifest, err)
+}
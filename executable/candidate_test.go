@@ -0,0 +1,99 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testCandidates(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	it("returns the exploded JAR as the only candidate", func() {
+		candidates, err := executable.Candidates(fstest.MapFS{
+			"META-INF/MANIFEST.MF": {Data: []byte("Main-Class: Foo")},
+			"test-1.jar":           {Data: JARBytes(t, map[string]string{"Main-Class": "Foo1"})},
+		}, "")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(candidates).To(HaveLen(1))
+		Expect(candidates[0].Path).To(Equal("."))
+		Expect(candidates[0].ExplodedJAR).To(BeTrue())
+		Expect(candidates[0].MainClass).To(Equal("Foo"))
+		Expect(candidates[0].Rejection).NotTo(HaveOccurred())
+	})
+
+	it("rejects an exploded JAR without Main-Class", func() {
+		candidates, err := executable.Candidates(fstest.MapFS{
+			"META-INF/MANIFEST.MF": {Data: []byte("foo: bar")},
+		}, "")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(candidates).To(HaveLen(1))
+		Expect(candidates[0].Rejection).To(MatchError(executable.ErrNoMainClass))
+		Expect(candidates[0].Properties.Map()).To(HaveKeyWithValue("foo", "bar"))
+	})
+
+	it("ranks glob matches first and records rejections", func() {
+		candidates, err := executable.Candidates(fstest.MapFS{
+			"a.jar":      {Data: JARBytes(t, map[string]string{"foo": "bar"})},
+			"b.jar":      {Data: []byte("not a zip")},
+			"c.jar":      {Data: JARBytes(t, map[string]string{"Main-Class": "C"})},
+			"lib/d.jar":  {Data: JARBytes(t, map[string]string{"Main-Class": "D"})},
+			"lib/readme": {Data: []byte("not a JAR")},
+		}, "lib/*")
+
+		Expect(err).NotTo(HaveOccurred())
+
+		var paths []string
+		for _, c := range candidates {
+			paths = append(paths, c.Path)
+		}
+		Expect(paths).To(Equal([]string{"lib/d.jar", "a.jar", "b.jar", "c.jar"}))
+
+		Expect(candidates[0].Matched).To(BeTrue())
+		Expect(candidates[0].Rejection).NotTo(HaveOccurred())
+		Expect(candidates[0].ExecutableJAR()).To(Equal(executable.ExecutableJAR{
+			MainClass:  "D",
+			Path:       "lib/d.jar",
+			Properties: candidates[0].Properties,
+			Executable: true,
+			Locator:    "glob",
+		}))
+		Expect(candidates[1].Matched).To(BeFalse())
+		Expect(candidates[1].Rejection).To(MatchError(executable.ErrNoMainClass))
+		Expect(candidates[2].Rejection).To(MatchError(executable.ErrInvalidManifest))
+		Expect(candidates[2].Properties).To(BeNil())
+		Expect(candidates[3].MainClass).To(Equal("C"))
+		Expect(candidates[3].Locator).To(Equal("jar-scan"))
+	})
+
+	it("returns no candidates for an empty application", func() {
+		candidates, err := executable.Candidates(fstest.MapFS{}, "")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(candidates).To(BeEmpty())
+	})
+}
@@ -70,3 +70,37 @@ func (c ClassPath) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
 func (ClassPath) Name() string {
 	return "classpath"
 }
+
+// RuntimeClassPath returns the absolute paths on the classpath of execJar when it runs: the JAR file or exploded JAR
+// followed by its Class-Path entries, which are relative to the directory containing the JAR file.
+func RuntimeClassPath(appPath string, execJar ExecutableJAR) []string {
+	classPath := []string{execJar.Path}
+
+	dir := appPath
+	if !execJar.ExplodedJAR {
+		dir = filepath.Dir(execJar.Path)
+	}
+
+	if execJar.Properties != nil {
+		for _, e := range strings.Fields(execJar.Properties.GetString("Class-Path", "")) {
+			e = filepath.FromSlash(strings.TrimPrefix(e, "file:"))
+			if !filepath.IsAbs(e) {
+				e = filepath.Join(dir, e)
+			}
+			classPath = append(classPath, e)
+		}
+	}
+
+	return classPath
+}
+
+// outside returns the entries of classPath that are not within dir.
+func outside(dir string, classPath []string) []string {
+	var entries []string
+	for _, c := range classPath {
+		if rel, err := filepath.Rel(dir, c); err != nil || !filepath.IsLocal(rel) {
+			entries = append(entries, c)
+		}
+	}
+	return entries
+}
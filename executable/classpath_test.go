@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/buildpacks/libcnb"
+	"github.com/magiconair/properties"
 	. "github.com/onsi/gomega"
 	"github.com/sclevine/spec"
 
@@ -79,4 +80,30 @@ func testClassPath(t *testing.T, context spec.G, it spec.S) {
 			Expect(layer.BuildEnvironment["CLASSPATH.prepend"]).To(Equal("test-value-1:test-value-2"))
 		})
 	})
+
+	context("RuntimeClassPath", func() {
+		it("resolves Class-Path entries against the directory of a JAR file", func() {
+			execJar := executable.ExecutableJAR{
+				Path:       "/workspace/target/app.jar",
+				Properties: properties.MustLoadString("Class-Path: lib/a.jar /opt/b.jar file:c.jar"),
+			}
+
+			Expect(executable.RuntimeClassPath("/workspace", execJar)).To(Equal([]string{
+				"/workspace/target/app.jar",
+				"/workspace/target/lib/a.jar",
+				"/opt/b.jar",
+				"/workspace/target/c.jar",
+			}))
+		})
+
+		it("resolves Class-Path entries against an exploded JAR", func() {
+			execJar := executable.ExecutableJAR{
+				Path:        "/workspace",
+				ExplodedJAR: true,
+				Properties:  properties.MustLoadString("Class-Path: lib/a.jar"),
+			}
+
+			Expect(executable.RuntimeClassPath("/workspace", execJar)).To(Equal([]string{"/workspace", "/workspace/lib/a.jar"}))
+		})
+	})
 }
@@ -0,0 +1,323 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+const (
+	// ConfigurationFile is the name of the configuration file in the application root.
+	ConfigurationFile = "executable-jar.toml"
+
+	// ProjectDescriptorTable is the table of project.toml containing the configuration.
+	ProjectDescriptorTable = "executable-jar"
+)
+
+// ProjectConfiguration is the configuration checked in with the application, either as ConfigurationFile or as the
+// ProjectDescriptorTable of project.toml. Every key corresponds to an environment variable, see README.md.
+type ProjectConfiguration struct {
+	Location              *string                         `toml:"location"`
+	MainClass             *string                         `toml:"main-class"`
+	Exclude               []string                        `toml:"exclude"`
+	JVMOptions            []string                        `toml:"jvm-options"`
+	DefaultProcess        *string                         `toml:"default-process"`
+	ApplicationType       *string                         `toml:"application-type"`
+	SBOM                  *string                         `toml:"sbom"`
+	SBOMFormats           []string                        `toml:"sbom-formats"`
+	SyftCache             *bool                           `toml:"syft-cache"`
+	LicenseAllow          []string                        `toml:"license-allow"`
+	LicenseDeny           []string                        `toml:"license-deny"`
+	VulnerabilitySeverity *string                         `toml:"vulnerability-severity"`
+	DangerousClasses      *string                         `toml:"dangerous-classes"`
+	VerifySignatures      *string                         `toml:"verify-signatures"`
+	Aliases               *bool                           `toml:"aliases"`
+	Processes             map[string]ProcessConfiguration `toml:"processes"`
+
+	Debug struct {
+		Enabled *bool   `toml:"enabled"`
+		Port    *int    `toml:"port"`
+		Suspend *bool   `toml:"suspend"`
+		Address *string `toml:"address"`
+	} `toml:"debug"`
+
+	Profile struct {
+		Enabled         *bool   `toml:"enabled"`
+		Duration        *string `toml:"duration"`
+		Settings        *string `toml:"settings"`
+		OutputDirectory *string `toml:"output-directory"`
+	} `toml:"profile"`
+
+	JMX struct {
+		Enabled *bool `toml:"enabled"`
+		Port    *int  `toml:"port"`
+	} `toml:"jmx"`
+
+	Reload struct {
+		Enabled     *bool    `toml:"enabled"`
+		WatchPaths  []string `toml:"watch-paths"`
+		Ignore      []string `toml:"ignore"`
+		GitIgnore   *bool    `toml:"gitignore"`
+		Debounce    *string  `toml:"debounce"`
+		StopSignal  *string  `toml:"stop-signal"`
+		StopTimeout *string  `toml:"stop-timeout"`
+		ClearScreen *bool    `toml:"clear-screen"`
+		PostRestart *string  `toml:"post-restart"`
+		Watcher     *string  `toml:"watcher"`
+		Explode     *bool    `toml:"explode"`
+		Permissions *string  `toml:"permissions"`
+	} `toml:"reload"`
+}
+
+// ProcessConfiguration is a process of the ProjectConfiguration.
+type ProcessConfiguration struct {
+	JVMOptions []string `toml:"jvm-options"`
+	Args       []string `toml:"args"`
+}
+
+// LoadProjectConfiguration reads the ProjectConfiguration of the application at appPath, returning the name of the
+// file it was read from, or an empty name if the application has none. ConfigurationFile takes precedence over
+// project.toml. Unknown keys and values of the wrong type are errors.
+func LoadProjectConfiguration(appPath string) (ProjectConfiguration, string, error) {
+	file := filepath.Join(appPath, ConfigurationFile)
+	if _, err := os.Stat(file); err == nil {
+		var c ProjectConfiguration
+		md, err := toml.DecodeFile(file, &c)
+		if err != nil {
+			return ProjectConfiguration{}, "", fmt.Errorf("unable to parse %s\n%w", ConfigurationFile, err)
+		}
+		if err := undecoded(md, ConfigurationFile, ""); err != nil {
+			return ProjectConfiguration{}, "", err
+		}
+		return c, ConfigurationFile, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return ProjectConfiguration{}, "", fmt.Errorf("unable to stat %s\n%w", file, err)
+	}
+
+	file = filepath.Join(appPath, "project.toml")
+	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+		return ProjectConfiguration{}, "", nil
+	} else if err != nil {
+		return ProjectConfiguration{}, "", fmt.Errorf("unable to stat %s\n%w", file, err)
+	}
+
+	var d struct {
+		ExecutableJAR *ProjectConfiguration `toml:"executable-jar"`
+	}
+	md, err := toml.DecodeFile(file, &d)
+	if err != nil {
+		return ProjectConfiguration{}, "", fmt.Errorf("unable to parse project.toml\n%w", err)
+	}
+	if d.ExecutableJAR == nil {
+		return ProjectConfiguration{}, "", nil
+	}
+	if err := undecoded(md, "project.toml", ProjectDescriptorTable); err != nil {
+		return ProjectConfiguration{}, "", err
+	}
+
+	return *d.ExecutableJAR, "project.toml", nil
+}
+
+// undecoded returns an error listing the keys below table that do not belong to the schema.
+func undecoded(md toml.MetaData, file string, table string) error {
+	var unknown []string
+	for _, k := range md.Undecoded() {
+		if table == "" || (len(k) > 1 && k[0] == table) {
+			unknown = append(unknown, k.String())
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown configuration in %s: %s", file, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Environment returns the configuration as the environment variables it corresponds to.
+func (p ProjectConfiguration) Environment() (map[string]string, error) {
+	env := map[string]string{}
+
+	setString := func(name string, value *string) {
+		if value != nil {
+			env[name] = *value
+		}
+	}
+	setBool := func(name string, value *bool) {
+		if value != nil {
+			env[name] = strconv.FormatBool(*value)
+		}
+	}
+	setInt := func(name string, value *int) {
+		if value != nil {
+			env[name] = strconv.Itoa(*value)
+		}
+	}
+	setList := func(name string, value []string) error {
+		if value == nil {
+			return nil
+		}
+		for _, v := range value {
+			if v == "" || strings.ContainsAny(v, ", \t\n") {
+				return fmt.Errorf("%q is not a valid entry of %s, entries must not be empty or contain commas or whitespace", v, name)
+			}
+		}
+		env[name] = strings.Join(value, ",")
+		return nil
+	}
+	setArguments := func(name string, value []string) {
+		if value != nil {
+			var s []string
+			for _, v := range value {
+				s = append(s, shellQuote(v))
+			}
+			env[name] = strings.Join(s, " ")
+		}
+	}
+
+	setString("BP_EXECUTABLE_JAR_LOCATION", p.Location)
+	setString("BP_EXECUTABLE_JAR_MAIN_CLASS", p.MainClass)
+	if err := setList("BP_EXECUTABLE_JAR_EXCLUDE", p.Exclude); err != nil {
+		return nil, err
+	}
+	setArguments("BP_EXECUTABLE_JAR_JVM_OPTIONS", p.JVMOptions)
+	setString("BP_EXECUTABLE_JAR_DEFAULT_PROCESS", p.DefaultProcess)
+	setString("BP_EXECUTABLE_JAR_APPLICATION_TYPE", p.ApplicationType)
+	setString("BP_EXECUTABLE_JAR_SBOM", p.SBOM)
+	if err := setList("BP_EXECUTABLE_JAR_SBOM_FORMATS", p.SBOMFormats); err != nil {
+		return nil, err
+	}
+	setBool("BP_EXECUTABLE_JAR_SYFT_CACHE_ENABLED", p.SyftCache)
+	if err := setList("BP_EXECUTABLE_JAR_LICENSE_ALLOW", p.LicenseAllow); err != nil {
+		return nil, err
+	}
+	if err := setList("BP_EXECUTABLE_JAR_LICENSE_DENY", p.LicenseDeny); err != nil {
+		return nil, err
+	}
+	setString("BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY", p.VulnerabilitySeverity)
+	setString("BP_EXECUTABLE_JAR_DANGEROUS_CLASSES", p.DangerousClasses)
+	setString("BP_EXECUTABLE_JAR_VERIFY_SIGNATURES", p.VerifySignatures)
+	setBool("BP_EXECUTABLE_JAR_ALIASES_ENABLED", p.Aliases)
+
+	if p.Processes != nil {
+		var types []string
+		for t := range p.Processes {
+			if !processType.MatchString(t) {
+				return nil, fmt.Errorf("%q is not a valid process type", t)
+			}
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		env["BP_EXECUTABLE_JAR_PROCESSES"] = strings.Join(types, ",")
+
+		for _, t := range types {
+			prefix := fmt.Sprintf("BP_EXECUTABLE_JAR_PROCESS_%s", processVariable(t))
+			setArguments(prefix+"_JVM_OPTIONS", p.Processes[t].JVMOptions)
+			setArguments(prefix+"_ARGS", p.Processes[t].Args)
+		}
+	}
+
+	setBool("BP_EXECUTABLE_JAR_DEBUG_ENABLED", p.Debug.Enabled)
+	setInt("BP_EXECUTABLE_JAR_DEBUG_PORT", p.Debug.Port)
+	setBool("BP_EXECUTABLE_JAR_DEBUG_SUSPEND", p.Debug.Suspend)
+	setString("BP_EXECUTABLE_JAR_DEBUG_ADDRESS", p.Debug.Address)
+
+	setBool("BP_EXECUTABLE_JAR_PROFILE_ENABLED", p.Profile.Enabled)
+	setString("BP_EXECUTABLE_JAR_PROFILE_DURATION", p.Profile.Duration)
+	setString("BP_EXECUTABLE_JAR_PROFILE_SETTINGS", p.Profile.Settings)
+	setString("BP_EXECUTABLE_JAR_PROFILE_OUTPUT_DIRECTORY", p.Profile.OutputDirectory)
+
+	setBool("BP_EXECUTABLE_JAR_JMX_ENABLED", p.JMX.Enabled)
+	setInt("BP_EXECUTABLE_JAR_JMX_PORT", p.JMX.Port)
+
+	setBool("BP_LIVE_RELOAD_ENABLED", p.Reload.Enabled)
+	if err := setList("BP_LIVE_RELOAD_WATCH_PATHS", p.Reload.WatchPaths); err != nil {
+		return nil, err
+	}
+	if err := setList("BP_LIVE_RELOAD_IGNORE", p.Reload.Ignore); err != nil {
+		return nil, err
+	}
+	setBool("BP_LIVE_RELOAD_GITIGNORE", p.Reload.GitIgnore)
+	setString("BP_LIVE_RELOAD_DEBOUNCE", p.Reload.Debounce)
+	setString("BP_LIVE_RELOAD_STOP_SIGNAL", p.Reload.StopSignal)
+	setString("BP_LIVE_RELOAD_STOP_TIMEOUT", p.Reload.StopTimeout)
+	setBool("BP_LIVE_RELOAD_CLEAR_SCREEN", p.Reload.ClearScreen)
+	setString("BP_LIVE_RELOAD_POST_RESTART", p.Reload.PostRestart)
+	setString("BP_LIVE_RELOAD_WATCHER", p.Reload.Watcher)
+	setBool("BP_LIVE_RELOAD_EXPLODE", p.Reload.Explode)
+	setString("BP_LIVE_RELOAD_PERMISSIONS", p.Reload.Permissions)
+
+	return env, nil
+}
+
+// NewConfigurationResolver creates a libpak.ConfigurationResolver for buildpack whose defaults are overridden by the
+// ProjectConfiguration of the application at appPath. Environment variables take precedence over both.
+func NewConfigurationResolver(buildpack libcnb.Buildpack, appPath string, logger *bard.Logger) (libpak.ConfigurationResolver, error) {
+	cr, err := libpak.NewConfigurationResolver(buildpack, logger)
+	if err != nil {
+		return libpak.ConfigurationResolver{}, err
+	}
+
+	p, file, err := LoadProjectConfiguration(appPath)
+	if err != nil {
+		return libpak.ConfigurationResolver{}, err
+	} else if file == "" {
+		return cr, nil
+	}
+
+	env, err := p.Environment()
+	if err != nil {
+		return libpak.ConfigurationResolver{}, fmt.Errorf("invalid configuration in %s\n%w", file, err)
+	}
+
+	var names []string
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if logger != nil {
+			if _, ok := os.LookupEnv(name); ok {
+				logger.Bodyf("$%s overrides its value in %s", name, file)
+			}
+		}
+
+		found := false
+		for i, c := range cr.Configurations {
+			if c.Name == name {
+				cr.Configurations[i].Default = env[name]
+				found = true
+			}
+		}
+		if !found {
+			cr.Configurations = append(cr.Configurations, libpak.BuildpackConfiguration{Name: name, Default: env[name]})
+		}
+	}
+
+	return cr, nil
+}
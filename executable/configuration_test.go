@@ -0,0 +1,260 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testConfiguration(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath string
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+	})
+
+	write := func(name string, content string) {
+		Expect(os.WriteFile(filepath.Join(appPath, name), []byte(content), 0644)).To(Succeed())
+	}
+
+	environment := func() map[string]string {
+		p, _, err := executable.LoadProjectConfiguration(appPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		env, err := p.Environment()
+		Expect(err).NotTo(HaveOccurred())
+		return env
+	}
+
+	it("has no configuration without a file", func() {
+		_, file, err := executable.LoadProjectConfiguration(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file).To(BeEmpty())
+	})
+
+	it("converts executable-jar.toml into environment variables", func() {
+		write("executable-jar.toml", `
+location     = "target/*.jar"
+main-class   = "com.example.Main"
+exclude      = ["*-sources.jar", "tools/*.jar"]
+jvm-options  = ["-Xmx1g", "-Dgreeting=hello world"]
+default-process = "worker"
+application-type = "cli"
+sbom         = "native"
+sbom-formats = ["cyclonedx", "spdx"]
+syft-cache   = true
+license-allow = ["Apache-2.0", "MIT"]
+license-deny = ["GPL-*"]
+vulnerability-severity = "critical"
+dangerous-classes = "remove"
+verify-signatures = "enforce"
+aliases      = false
+
+[processes.worker]
+jvm-options = ["-Xss1m"]
+args        = ["--queue", "jobs"]
+
+[processes.db-migrate]
+args = ["migrate"]
+
+[debug]
+enabled = true
+port    = 5005
+
+[profile]
+duration = "60s"
+
+[jmx]
+port = 9999
+
+[reload]
+enabled     = true
+watch-paths = ["classes"]
+gitignore   = false
+debounce    = "500ms"
+watcher     = "builtin"
+permissions = "0066"
+`)
+
+		Expect(environment()).To(Equal(map[string]string{
+			"BP_EXECUTABLE_JAR_LOCATION":                   "target/*.jar",
+			"BP_EXECUTABLE_JAR_MAIN_CLASS":                 "com.example.Main",
+			"BP_EXECUTABLE_JAR_EXCLUDE":                    "*-sources.jar,tools/*.jar",
+			"BP_EXECUTABLE_JAR_JVM_OPTIONS":                "-Xmx1g '-Dgreeting=hello world'",
+			"BP_EXECUTABLE_JAR_DEFAULT_PROCESS":            "worker",
+			"BP_EXECUTABLE_JAR_APPLICATION_TYPE":           "cli",
+			"BP_EXECUTABLE_JAR_SBOM":                       "native",
+			"BP_EXECUTABLE_JAR_SBOM_FORMATS":               "cyclonedx,spdx",
+			"BP_EXECUTABLE_JAR_SYFT_CACHE_ENABLED":         "true",
+			"BP_EXECUTABLE_JAR_LICENSE_ALLOW":              "Apache-2.0,MIT",
+			"BP_EXECUTABLE_JAR_LICENSE_DENY":               "GPL-*",
+			"BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY":     "critical",
+			"BP_EXECUTABLE_JAR_DANGEROUS_CLASSES":          "remove",
+			"BP_EXECUTABLE_JAR_VERIFY_SIGNATURES":          "enforce",
+			"BP_EXECUTABLE_JAR_ALIASES_ENABLED":            "false",
+			"BP_EXECUTABLE_JAR_PROCESSES":                  "db-migrate,worker",
+			"BP_EXECUTABLE_JAR_PROCESS_DB_MIGRATE_ARGS":    "migrate",
+			"BP_EXECUTABLE_JAR_PROCESS_WORKER_JVM_OPTIONS": "-Xss1m",
+			"BP_EXECUTABLE_JAR_PROCESS_WORKER_ARGS":        "--queue jobs",
+			"BP_EXECUTABLE_JAR_DEBUG_ENABLED":              "true",
+			"BP_EXECUTABLE_JAR_DEBUG_PORT":                 "5005",
+			"BP_EXECUTABLE_JAR_PROFILE_DURATION":           "60s",
+			"BP_EXECUTABLE_JAR_JMX_PORT":                   "9999",
+			"BP_LIVE_RELOAD_ENABLED":                       "true",
+			"BP_LIVE_RELOAD_WATCH_PATHS":                   "classes",
+			"BP_LIVE_RELOAD_GITIGNORE":                     "false",
+			"BP_LIVE_RELOAD_DEBOUNCE":                      "500ms",
+			"BP_LIVE_RELOAD_WATCHER":                       "builtin",
+			"BP_LIVE_RELOAD_PERMISSIONS":                   "0066",
+		}))
+	})
+
+	it("reads the executable-jar table of project.toml", func() {
+		write("project.toml", `
+[_]
+id = "test-project"
+
+[[io.buildpacks.build.env]]
+name  = "BP_JVM_VERSION"
+value = "17"
+
+[executable-jar]
+main-class = "com.example.Main"
+`)
+
+		p, file, err := executable.LoadProjectConfiguration(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file).To(Equal("project.toml"))
+		Expect(p.Environment()).To(Equal(map[string]string{"BP_EXECUTABLE_JAR_MAIN_CLASS": "com.example.Main"}))
+	})
+
+	it("ignores project.toml without an executable-jar table", func() {
+		write("project.toml", "[_]\nid = \"test-project\"\n")
+
+		_, file, err := executable.LoadProjectConfiguration(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file).To(BeEmpty())
+	})
+
+	it("prefers executable-jar.toml to project.toml", func() {
+		write("project.toml", "[executable-jar]\nmain-class = \"com.example.Project\"\n")
+		write("executable-jar.toml", "main-class = \"com.example.File\"\n")
+
+		_, file, err := executable.LoadProjectConfiguration(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file).To(Equal("executable-jar.toml"))
+		Expect(environment()).To(Equal(map[string]string{"BP_EXECUTABLE_JAR_MAIN_CLASS": "com.example.File"}))
+	})
+
+	context("validation", func() {
+		it("rejects unknown keys", func() {
+			write("executable-jar.toml", "main_class = \"com.example.Main\"\n[reload]\nwatch = [\"classes\"]\n")
+
+			_, _, err := executable.LoadProjectConfiguration(appPath)
+			Expect(err).To(MatchError("unknown configuration in executable-jar.toml: main_class, reload.watch"))
+		})
+
+		it("rejects unknown keys in project.toml", func() {
+			write("project.toml", "[executable-jar.processes.worker]\njvm-opts = [\"-Xmx1g\"]\n")
+
+			_, _, err := executable.LoadProjectConfiguration(appPath)
+			Expect(err).To(MatchError("unknown configuration in project.toml: executable-jar.processes.worker.jvm-opts"))
+		})
+
+		it("rejects values of the wrong type", func() {
+			write("executable-jar.toml", "[debug]\nport = \"5005\"\n")
+
+			_, _, err := executable.LoadProjectConfiguration(appPath)
+			Expect(err).To(MatchError(ContainSubstring("unable to parse executable-jar.toml")))
+		})
+
+		it("rejects list entries containing commas", func() {
+			write("executable-jar.toml", "exclude = [\"a.jar,b.jar\"]\n")
+
+			p, _, err := executable.LoadProjectConfiguration(appPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = p.Environment()
+			Expect(err).To(MatchError(ContainSubstring(`"a.jar,b.jar" is not a valid entry of BP_EXECUTABLE_JAR_EXCLUDE`)))
+		})
+
+		it("rejects invalid process types", func() {
+			write("executable-jar.toml", "[processes.\"worker/1\"]\nargs = []\n")
+
+			p, _, err := executable.LoadProjectConfiguration(appPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = p.Environment()
+			Expect(err).To(MatchError(`"worker/1" is not a valid process type`))
+		})
+	})
+
+	context("NewConfigurationResolver", func() {
+		var buildpack libcnb.Buildpack
+
+		it.Before(func() {
+			buildpack.Metadata = map[string]interface{}{
+				"configurations": []map[string]interface{}{
+					{"name": "BP_EXECUTABLE_JAR_LOCATION", "default": ""},
+					{"name": "BP_LIVE_RELOAD_GITIGNORE", "default": "true"},
+				},
+			}
+		})
+
+		it("overrides the buildpack defaults with the file", func() {
+			write("executable-jar.toml", "location = \"target/*.jar\"\nmain-class = \"com.example.Main\"\n[reload]\ngitignore = false\n")
+
+			cr, err := executable.NewConfigurationResolver(buildpack, appPath, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cr.Resolve("BP_EXECUTABLE_JAR_LOCATION")).To(Equal("target/*.jar"))
+			Expect(cr.Resolve("BP_EXECUTABLE_JAR_MAIN_CLASS")).To(Equal("com.example.Main"))
+			Expect(cr.ResolveBool("BP_LIVE_RELOAD_GITIGNORE")).To(BeFalse())
+		})
+
+		it("prefers environment variables to the file", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_LOCATION", "build/libs/*.jar")
+
+			write("executable-jar.toml", "location = \"target/*.jar\"\n")
+
+			cr, err := executable.NewConfigurationResolver(buildpack, appPath, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s, _ := cr.Resolve("BP_EXECUTABLE_JAR_LOCATION")
+			Expect(s).To(Equal("build/libs/*.jar"))
+		})
+
+		it("names the file in errors", func() {
+			write("executable-jar.toml", "exclude = [\"\"]\n")
+
+			_, err := executable.NewConfigurationResolver(buildpack, appPath, nil)
+			Expect(err).To(MatchError(ContainSubstring("invalid configuration in executable-jar.toml")))
+		})
+	})
+}
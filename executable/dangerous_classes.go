@@ -0,0 +1,359 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"archive/zip"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+)
+
+// ClassSignaturesBindingType is the type of the binding holding additional class signatures, JSON arrays of
+// ClassSignature.
+const ClassSignaturesBindingType = "class-signatures"
+
+// The values of $BP_EXECUTABLE_JAR_DANGEROUS_CLASSES, what to do with the dangerous classes that are found.
+const (
+	DangerousClassesFail   = "fail"
+	DangerousClassesRemove = "remove"
+	DangerousClassesWarn   = "warn"
+)
+
+//go:embed signatures.json
+var defaultClassSignatures []byte
+
+// ClassSignature identifies a dangerous class by its path within an archive and the versions of the library that
+// contain it.
+type ClassSignature struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+
+	// Class is the path of the class within an archive, such as org/example/Dangerous.class.
+	Class string `json:"class"`
+
+	// Affected is the Maven package and versions that contain the dangerous class. A class is dangerous in every
+	// archive if there is no package, and in archives whose version of the package is not known.
+	Affected AdvisoryAffected `json:"affected"`
+}
+
+// DangerousClass is a dangerous class found in an archive.
+type DangerousClass struct {
+	Signature ClassSignature
+
+	// Location is the location of the archive containing the class, see Package.
+	Location string
+
+	// Version is the version of the package of the signature in the archive, empty if it is not known.
+	Version string
+}
+
+func (d DangerousClass) String() string {
+	version := d.Version
+	if version == "" {
+		version = "unknown version"
+	}
+	return fmt.Sprintf("%s: %s in %s (%s): %s", d.Signature.ID, d.Signature.Class, d.Location, version, d.Signature.Description)
+}
+
+// LoadClassSignatures returns the signatures that ship with the buildpack followed by those in the files of the
+// class-signatures binding, if there is one.
+func LoadClassSignatures(binds libcnb.Bindings) ([]ClassSignature, error) {
+	var signatures []ClassSignature
+	if err := json.Unmarshal(defaultClassSignatures, &signatures); err != nil {
+		return nil, fmt.Errorf("unable to parse class signatures\n%w", err)
+	}
+
+	for _, b := range binds {
+		if !strings.EqualFold(b.Type, ClassSignaturesBindingType) {
+			continue
+		}
+
+		var keys []string
+		for k := range b.Secret {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			var s []ClassSignature
+			if err := json.Unmarshal([]byte(b.Secret[k]), &s); err != nil {
+				return nil, fmt.Errorf("unable to parse %s of binding %s\n%w", k, b.Name, err)
+			}
+			signatures = append(signatures, s...)
+		}
+	}
+
+	return signatures, nil
+}
+
+// dangerousClassesInDirectory returns the dangerous classes at the root of an exploded JAR in fsys at location.
+func dangerousClassesInDirectory(fsys fs.FS, location string, signatures []ClassSignature) []DangerousClass {
+	var found []DangerousClass
+
+	for _, s := range signatures {
+		if fi, err := fs.Stat(fsys, s.Class); err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+
+		version := ""
+		if group, artifact, ok := strings.Cut(s.Affected.Package.Name, ":"); ok {
+			if b, err := fs.ReadFile(fsys, path.Join("META-INF", "maven", group, artifact, "pom.properties")); err == nil {
+				if p, ok := packageFromPOM(b, ""); ok {
+					version = p.Version
+				}
+			}
+		}
+
+		if s.dangerous(version) {
+			found = append(found, DangerousClass{Signature: s, Location: location, Version: version})
+		}
+	}
+
+	return found
+}
+
+// dangerousClassesInArchive returns the dangerous classes in the archive z at location, not including those in the
+// archives nested within it.
+func dangerousClassesInArchive(z *zip.Reader, location string, signatures []ClassSignature) ([]DangerousClass, error) {
+	entries := map[string]*zip.File{}
+	for _, f := range z.File {
+		entries[f.Name] = f
+	}
+
+	var found []DangerousClass
+	for _, s := range signatures {
+		if entries[s.Class] == nil {
+			continue
+		}
+
+		version := ""
+		if group, artifact, ok := strings.Cut(s.Affected.Package.Name, ":"); ok {
+			if f := entries[path.Join("META-INF", "maven", group, artifact, "pom.properties")]; f != nil {
+				c, err := readEntry(f)
+				if err != nil {
+					return nil, fmt.Errorf("unable to read %s in %s\n%w", f.Name, location, err)
+				}
+				if p, ok := packageFromPOM(c, location); ok {
+					version = p.Version
+				}
+			} else if p := packageFromFileName(location); p.Name == artifact {
+				version = p.Version
+			}
+		}
+
+		if s.dangerous(version) {
+			found = append(found, DangerousClass{Signature: s, Location: location, Version: version})
+		}
+	}
+
+	return found, nil
+}
+
+// dangerous reports whether the class of the signature is dangerous in an archive with version of its package.
+func (s ClassSignature) dangerous(version string) bool {
+	if s.Affected.Package.Name == "" || version == "" {
+		return true
+	}
+
+	_, ok := s.Affected.Affects(version)
+	return ok
+}
+
+// RemoveDangerousClass rewrites the archive containing the dangerous class to remove it, or removes the class file of
+// an exploded JAR. Archives nested within the archive are rewritten with the same compression method, so that
+// uncompressed nested archives can still be read in place.
+func RemoveDangerousClass(scanDir string, d DangerousClass) error {
+	parts := strings.Split(d.Location, ":")
+
+	file := filepath.FromSlash(parts[0])
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(scanDir, file)
+	}
+
+	fi, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("unable to stat %s\n%w", file, err)
+	}
+
+	if fi.IsDir() {
+		if err := os.Remove(filepath.Join(file, filepath.FromSlash(d.Signature.Class))); err != nil {
+			return fmt.Errorf("unable to remove %s\n%w", d.Signature.Class, err)
+		}
+		return nil
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	b, err = removeEntry(b, parts[1:], d.Signature.Class)
+	if err != nil {
+		return fmt.Errorf("unable to remove %s from %s\n%w", d.Signature.Class, d.Location, err)
+	}
+
+	if err := replaceFile(file, b, fi.Mode().Perm()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// replaceFile replaces file with b through a temporary file in the same directory, so that file is either unchanged or
+// completely written.
+func replaceFile(file string, b []byte, perm fs.FileMode) error {
+	out, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file for %s\n%w", file, err)
+	}
+	defer os.Remove(out.Name())
+
+	if _, err := out.Write(b); err != nil {
+		out.Close()
+		return fmt.Errorf("unable to write %s\n%w", out.Name(), err)
+	}
+	if err := out.Chmod(perm); err != nil {
+		out.Close()
+		return fmt.Errorf("unable to chmod %s\n%w", out.Name(), err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("unable to close %s\n%w", out.Name(), err)
+	}
+
+	if err := os.Rename(out.Name(), file); err != nil {
+		return fmt.Errorf("unable to replace %s\n%w", file, err)
+	}
+	return nil
+}
+
+// removeEntry returns the archive b without the entry name of the archive nested at the path of nested. Anything
+// before the first entry, such as the launch script of a fully executable JAR, is kept.
+func removeEntry(b []byte, nested []string, name string) ([]byte, error) {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := archivePrefix(b, z)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	out.Write(prefix)
+	w := zip.NewWriter(out)
+	w.SetOffset(int64(len(prefix)))
+	w.SetComment(z.Comment)
+
+	for _, f := range z.File {
+		switch {
+		case len(nested) == 0 && f.Name == name:
+			continue
+
+		case len(nested) > 0 && f.Name == nested[0]:
+			c, err := readEntry(f)
+			if err != nil {
+				return nil, err
+			}
+			if c, err = removeEntry(c, nested[1:], name); err != nil {
+				return nil, err
+			}
+
+			h := f.FileHeader
+			h.Flags &^= 0x8
+			h.CRC32 = crc32.ChecksumIEEE(c)
+			h.UncompressedSize64 = uint64(len(c))
+
+			if h.Method == zip.Store {
+				h.CompressedSize64 = h.UncompressedSize64
+				e, err := w.CreateRaw(&h)
+				if err != nil {
+					return nil, err
+				}
+				if _, err := e.Write(c); err != nil {
+					return nil, err
+				}
+			} else {
+				e, err := w.CreateHeader(&h)
+				if err != nil {
+					return nil, err
+				}
+				if _, err := io.Copy(e, bytes.NewReader(c)); err != nil {
+					return nil, err
+				}
+			}
+
+		default:
+			if err := w.Copy(f); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// archivePrefix returns the bytes of the archive b, read by z, before its first local file header.
+func archivePrefix(b []byte, z *zip.Reader) ([]byte, error) {
+	if len(z.File) == 0 {
+		return nil, nil
+	}
+
+	first := int64(len(b))
+	for _, f := range z.File {
+		o, err := f.DataOffset()
+		if err != nil {
+			return nil, err
+		}
+		first = min(first, o)
+	}
+
+	i := bytes.Index(b[:first], []byte("PK\x03\x04"))
+	if i < 0 {
+		return nil, fmt.Errorf("unable to find the first local file header")
+	}
+	return b[:i], nil
+}
+
+// resolveDangerousClasses returns the value of $BP_EXECUTABLE_JAR_DANGEROUS_CLASSES.
+func resolveDangerousClasses(cr libpak.ConfigurationResolver) (string, error) {
+	s, _ := cr.Resolve("BP_EXECUTABLE_JAR_DANGEROUS_CLASSES")
+	switch s {
+	case "", DangerousClassesFail:
+		return DangerousClassesFail, nil
+	case DangerousClassesRemove, DangerousClassesWarn:
+		return s, nil
+	default:
+		return "", fmt.Errorf("$BP_EXECUTABLE_JAR_DANGEROUS_CLASSES must be fail, remove or warn, not %q", s)
+	}
+}
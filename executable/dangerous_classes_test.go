@@ -0,0 +1,209 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+const jndiLookup = "org/apache/logging/log4j/core/lookup/JndiLookup.class"
+
+// log4jCore returns the contents of a log4j-core JAR file of version, or without a pom.properties if version is empty
+func log4jCore(t *testing.T, version string) string {
+	entries := map[string]string{
+		jndiLookup: "jndi",
+		"org/apache/logging/log4j/core/Logger.class": "logger",
+	}
+	if version != "" {
+		entries["META-INF/maven/org.apache.logging.log4j/log4j-core/pom.properties"] =
+			"groupId=org.apache.logging.log4j\nartifactId=log4j-core\nversion=" + version + "\n"
+	}
+
+	file := filepath.Join(t.TempDir(), "log4j-core.jar")
+	CreateZIP(t, file, entries)
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func testDangerousClasses(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath    string
+		signatures []executable.ClassSignature
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+
+		var err error
+		signatures, err = executable.LoadClassSignatures(nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	find := func() []executable.DangerousClass {
+		inspection, err := executable.Inspect(appPath, nil, signatures, false, nil)
+		Expect(err).NotTo(HaveOccurred())
+		return inspection.DangerousClasses
+	}
+
+	it("finds JndiLookup in vulnerable versions of log4j-core", func() {
+		CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+			"BOOT-INF/lib/log4j-core-2.14.1.jar": log4jCore(t, "2.14.1"),
+			"BOOT-INF/lib/log4j-core-2.17.1.jar": log4jCore(t, "2.17.1"),
+			"BOOT-INF/lib/shaded.jar":            log4jCore(t, ""),
+		})
+		CreateZIP(t, filepath.Join(appPath, "log4j-core-2.12.2.jar"), map[string]string{jndiLookup: ""})
+		CreateZIP(t, filepath.Join(appPath, "log4j-core-2.11.0.jar"), map[string]string{jndiLookup: ""})
+
+		Expect(find()).To(Equal([]executable.DangerousClass{
+			{Signature: signatures[0], Location: "app.jar:BOOT-INF/lib/log4j-core-2.14.1.jar", Version: "2.14.1"},
+			{Signature: signatures[0], Location: "app.jar:BOOT-INF/lib/shaded.jar"},
+			{Signature: signatures[0], Location: "log4j-core-2.11.0.jar", Version: "2.11.0"},
+		}))
+	})
+
+	it("finds classes in exploded JARs", func() {
+		Expect(os.MkdirAll(filepath.Join(appPath, filepath.Dir(jndiLookup)), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, jndiLookup), []byte{}, 0644)).To(Succeed())
+
+		found := find()
+		Expect(found).To(Equal([]executable.DangerousClass{{Signature: signatures[0], Location: "."}}))
+
+		Expect(executable.RemoveDangerousClass(appPath, found[0])).To(Succeed())
+		Expect(filepath.Join(appPath, jndiLookup)).NotTo(BeAnExistingFile())
+	})
+
+	it("finds classes on the classpath outside of the application", func() {
+		external := filepath.Join(t.TempDir(), "log4j-core-2.14.1.jar")
+		Expect(os.WriteFile(external, []byte(log4jCore(t, "2.14.1")), 0644)).To(Succeed())
+
+		inspection, err := executable.Inspect(appPath, []string{external}, signatures, false, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inspection.DangerousClasses).To(ConsistOf(HaveField("Location", filepath.ToSlash(external))))
+	})
+
+	it("loads additional signatures from a binding", func() {
+		signatures, err := executable.LoadClassSignatures(libcnb.Bindings{
+			libcnb.NewBinding("signatures", t.TempDir(), map[string]string{
+				"type":            executable.ClassSignaturesBindingType,
+				"signatures.json": `[{"id": "example", "description": "always dangerous", "class": "org/example/Dangerous.class"}]`,
+			}),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signatures).To(HaveLen(2))
+
+		CreateZIP(t, filepath.Join(appPath, "lib-1.0.jar"), map[string]string{"org/example/Dangerous.class": ""})
+
+		inspection, err := executable.Inspect(appPath, nil, signatures, false, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inspection.DangerousClasses).To(Equal([]executable.DangerousClass{{Signature: signatures[1], Location: "lib-1.0.jar", Version: ""}}))
+	})
+
+	it("removes classes from nested archives, keeping them uncompressed", func() {
+		out := &bytes.Buffer{}
+		z := zip.NewWriter(out)
+		for _, e := range []struct {
+			name    string
+			content string
+		}{
+			{"META-INF/MANIFEST.MF", "Main-Class: test.Main\n"},
+			{"BOOT-INF/lib/log4j-core-2.14.1.jar", log4jCore(t, "2.14.1")},
+		} {
+			w, err := z.CreateHeader(&zip.FileHeader{Name: e.name, Method: zip.Store})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write([]byte(e.content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(z.Close()).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "app.jar"), out.Bytes(), 0644)).To(Succeed())
+
+		found := find()
+		Expect(found).To(HaveLen(1))
+		Expect(executable.RemoveDangerousClass(appPath, found[0])).To(Succeed())
+		Expect(find()).To(BeEmpty())
+
+		outer, err := zip.OpenReader(filepath.Join(appPath, "app.jar"))
+		Expect(err).NotTo(HaveOccurred())
+		defer outer.Close()
+		Expect(outer.File).To(HaveLen(2))
+		Expect(outer.File[1].Method).To(Equal(zip.Store))
+
+		r, err := outer.File[1].Open()
+		Expect(err).NotTo(HaveOccurred())
+		b, err := io.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+
+		inner, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, f := range inner.File {
+			names = append(names, f.Name)
+		}
+		Expect(names).To(ConsistOf(
+			"META-INF/maven/org.apache.logging.log4j/log4j-core/pom.properties",
+			"org/apache/logging/log4j/core/Logger.class",
+		))
+	})
+	it("keeps the launch script of a fully executable JAR", func() {
+		script := "#!/bin/bash\nexec java -jar \"$0\" \"$@\"\n"
+		jar := filepath.Join(appPath, "log4j-core-2.14.1.jar")
+		Expect(os.WriteFile(jar, []byte(script+log4jCore(t, "2.14.1")), 0755)).To(Succeed())
+
+		found := find()
+		Expect(found).To(HaveLen(1))
+		Expect(executable.RemoveDangerousClass(appPath, found[0])).To(Succeed())
+		Expect(find()).To(BeEmpty())
+
+		b, err := os.ReadFile(jar)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(HavePrefix(script))
+
+		z, err := zip.OpenReader(jar)
+		Expect(err).NotTo(HaveOccurred())
+		defer z.Close()
+		Expect(z.File).To(HaveLen(2))
+		for _, f := range z.File {
+			o, err := f.DataOffset()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(o).To(BeNumerically(">", len(script)))
+		}
+
+		fi, err := os.Stat(jar)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0755)))
+
+		entries, err := os.ReadDir(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak"
+)
+
+// Debug is the $BP_EXECUTABLE_JAR_DEBUG_* configuration of the Java Debug Wire Protocol agent used by the debug
+// processes.
+type Debug struct {
+	// Port is the port the agent listens on.
+	Port int
+
+	// Suspend suspends the JVM until a debugger attaches.
+	Suspend bool
+
+	// Address is the address the agent binds to, "*" for all addresses.
+	Address string
+
+	// JVMVersion is the version of the JVM the application runs on, $BP_JVM_VERSION, or empty if unknown.
+	JVMVersion string
+}
+
+// NewDebug reads the debug configuration, returning an error for invalid values.
+func NewDebug(cr libpak.ConfigurationResolver) (Debug, error) {
+	d := Debug{Port: 8000, Address: "*", Suspend: cr.ResolveBool("BP_EXECUTABLE_JAR_DEBUG_SUSPEND")}
+	d.JVMVersion, _ = cr.Resolve("BP_JVM_VERSION")
+
+	if s, _ := cr.Resolve("BP_EXECUTABLE_JAR_DEBUG_PORT"); s != "" {
+		p, err := strconv.Atoi(s)
+		if err != nil || p < 1 || p > 65535 {
+			return Debug{}, fmt.Errorf("$BP_EXECUTABLE_JAR_DEBUG_PORT must be a port number, not %q", s)
+		}
+		d.Port = p
+	}
+
+	if s, _ := cr.Resolve("BP_EXECUTABLE_JAR_DEBUG_ADDRESS"); s != "" {
+		if strings.ContainsAny(s, ", \t") {
+			return Debug{}, fmt.Errorf("$BP_EXECUTABLE_JAR_DEBUG_ADDRESS must be a host name or IP address, not %q", s)
+		}
+		d.Address = s
+	}
+
+	return d, nil
+}
+
+// Argument returns the -agentlib:jdwp argument. Java 8 and earlier only accept a host name in the address and listen on
+// all addresses if there is none, while later versions listen on loopback only if there is none. The version of the
+// class files of the application says nothing about the JVM it runs on, so the wildcard is only omitted if the JVM is
+// known to be Java 8 or earlier.
+func (d Debug) Argument() string {
+	suspend := "n"
+	if d.Suspend {
+		suspend = "y"
+	}
+
+	address := fmt.Sprintf("%s:%d", d.Address, d.Port)
+	if d.Address == "*" && isJava8OrEarlier(d.JVMVersion) {
+		address = strconv.Itoa(d.Port)
+	}
+
+	return fmt.Sprintf("-agentlib:jdwp=transport=dt_socket,server=y,suspend=%s,address=%s", suspend, address)
+}
+
+// isJava8OrEarlier reports whether version, such as 1.8, 8, 8.* or 8.0.392, is Java 8 or earlier.
+func isJava8OrEarlier(version string) bool {
+	version = strings.TrimSpace(version)
+	if strings.HasPrefix(version, "1.") {
+		return true
+	}
+
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	return err == nil && n <= 8
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testDebug(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr libpak.ConfigurationResolver
+	)
+
+	it("listens on all addresses by default", func() {
+		d, err := executable.NewDebug(cr)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d).To(Equal(executable.Debug{Port: 8000, Address: "*"}))
+		Expect(d.Argument()).To(Equal("-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:8000"))
+	})
+
+	it("uses the configuration", func() {
+		t.Setenv("BP_EXECUTABLE_JAR_DEBUG_PORT", "5005")
+		t.Setenv("BP_EXECUTABLE_JAR_DEBUG_SUSPEND", "true")
+		t.Setenv("BP_EXECUTABLE_JAR_DEBUG_ADDRESS", "127.0.0.1")
+
+		d, err := executable.NewDebug(cr)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d.Argument()).To(Equal("-agentlib:jdwp=transport=dt_socket,server=y,suspend=y,address=127.0.0.1:5005"))
+	})
+
+	it("omits the wildcard address if $BP_JVM_VERSION is Java 8", func() {
+		for _, s := range []string{"8", "1.8", "8.*", "8.0.392"} {
+			t.Setenv("BP_JVM_VERSION", s)
+
+			d, err := executable.NewDebug(cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.Argument()).To(Equal("-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=8000"), s)
+		}
+	})
+
+	it("uses the wildcard address if $BP_JVM_VERSION is later than Java 8", func() {
+		for _, s := range []string{"11", "17.*", "21.0.1"} {
+			t.Setenv("BP_JVM_VERSION", s)
+
+			d, err := executable.NewDebug(cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.Argument()).To(Equal("-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:8000"), s)
+		}
+	})
+
+	it("fails for an invalid port", func() {
+		for _, s := range []string{"debug", "0", "65536"} {
+			t.Setenv("BP_EXECUTABLE_JAR_DEBUG_PORT", s)
+
+			_, err := executable.NewDebug(cr)
+			Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_DEBUG_PORT must be a port number")))
+		}
+	})
+
+	it("fails for an invalid address", func() {
+		t.Setenv("BP_EXECUTABLE_JAR_DEBUG_ADDRESS", "localhost,suspend=y")
+
+		_, err := executable.NewDebug(cr)
+		Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_DEBUG_ADDRESS must be")))
+	})
+}
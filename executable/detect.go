@@ -17,11 +17,10 @@
 package executable
 
 import (
+	"errors"
 	"fmt"
-	"reflect"
 
 	"github.com/buildpacks/libcnb"
-	"github.com/paketo-buildpacks/libpak"
 	"github.com/paketo-buildpacks/libpak/bard"
 )
 
@@ -35,10 +34,13 @@ const (
 
 type Detect struct {
 	Logger bard.Logger
+
+	// Locators are the strategies used to find the executable JAR, DefaultLocators if nil.
+	Locators []Locator
 }
 
 func (d Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error) {
-	cr, err := libpak.NewConfigurationResolver(context.Buildpack, &d.Logger)
+	cr, err := NewConfigurationResolver(context.Buildpack, context.Application.Path, &d.Logger)
 	if err != nil {
 		return libcnb.DetectResult{}, fmt.Errorf("unable to create configuration resolver\n%w", err)
 	}
@@ -51,7 +53,6 @@ func (d Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error
 					{Name: PlanEntryJVMApplication},
 				},
 				Requires: []libcnb.BuildPlanRequire{
-					{Name: PlanEntrySyft},
 					{Name: PlanEntryJRE, Metadata: map[string]interface{}{"launch": true}},
 					{Name: PlanEntryJVMApplicationPackage},
 					{Name: PlanEntryJVMApplication},
@@ -60,18 +61,37 @@ func (d Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error
 		},
 	}
 
-	jarGlob, _ := cr.Resolve("BP_EXECUTABLE_JAR_LOCATION")
-	execJar, err := LoadExecutableJAR(context.Application.Path, jarGlob)
-	if err != nil {
-		return libcnb.DetectResult{}, fmt.Errorf("unable to load executable JAR\n%w", err)
+	// syft is only needed to write the SBOM
+	if scanner, err := resolveSBOM(cr); err != nil {
+		return libcnb.DetectResult{}, err
+	} else if scanner == SBOMSyft {
+		result.Plans[0].Requires = append([]libcnb.BuildPlanRequire{{Name: PlanEntrySyft}}, result.Plans[0].Requires...)
 	}
 
-	if !reflect.DeepEqual(execJar, ExecutableJAR{}) {
-		d.Logger.Info("PASSED: 'Main-Class' manifest attribute found")
+	lc := NewLocatorContext(cr)
+	lc.Logger = d.Logger
+	if execJar, err := loadExecutableJAR(context.Application.Path, lc, d.Locators); err != nil && !errors.Is(err, ErrNoExecutableJAR) {
+		return libcnb.DetectResult{}, fmt.Errorf("unable to load executable JAR\n%w", err)
+	} else if err == nil {
+		d.Logger.Infof("PASSED: main class %s found by the %s locator", execJar.MainClass, execJar.Locator)
 		result.Plans[0].Provides = append(result.Plans[0].Provides, libcnb.BuildPlanProvide{Name: PlanEntryJVMApplicationPackage})
+
+		// plan provisions cannot carry metadata, so downstream buildpacks receive it through this requirement
+		for i, r := range result.Plans[0].Requires {
+			if r.Name == PlanEntryJVMApplicationPackage {
+				result.Plans[0].Requires[i].Metadata = NewPackageMetadata(context.Application.Path, execJar).Metadata()
+			}
+		}
 	}
 
 	if cr.ResolveBool("BP_LIVE_RELOAD_ENABLED") {
+		watcher, err := resolveWatcher(cr)
+		if err != nil {
+			return libcnb.DetectResult{}, err
+		} else if watcher == WatcherBuiltin {
+			return result, nil
+		}
+
 		for i := range result.Plans {
 			result.Plans[i].Requires = append(result.Plans[i].Requires, libcnb.BuildPlanRequire{
 				Name: PlanEntryWatchexec,
@@ -120,7 +120,12 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 						Requires: []libcnb.BuildPlanRequire{
 							{Name: "syft"},
 							{Name: "jre", Metadata: map[string]interface{}{"launch": true}},
-							{Name: "jvm-application-package"},
+							{Name: "jvm-application-package", Metadata: map[string]interface{}{
+								"path":       ".",
+								"mode":       "exploded",
+								"main-class": "test-main-class",
+								"locator":    "exploded-jar",
+							}},
 							{Name: "jvm-application"},
 						},
 					},
@@ -146,7 +151,12 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 						Requires: []libcnb.BuildPlanRequire{
 							{Name: "syft"},
 							{Name: "jre", Metadata: map[string]interface{}{"launch": true}},
-							{Name: "jvm-application-package"},
+							{Name: "jvm-application-package", Metadata: map[string]interface{}{
+								"path":       "a.jar",
+								"mode":       "jar",
+								"main-class": "test.Main",
+								"locator":    "jar-scan",
+							}},
 							{Name: "jvm-application"},
 						},
 					},
@@ -155,13 +165,63 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
-	context("$BP_LIVE_RELOAD_ENABLED is set", func() {
+	context("custom locators", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(path, "app.bin"), []byte{}, 0644)).To(Succeed())
+			detect.Locators = []executable.Locator{
+				fakeLocator{name: "in-house", candidate: executable.Candidate{Path: "app.bin", MainClass: "test.Main"}},
+			}
+		})
+
+		it("provides jvm-application-package", func() {
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Plans[0].Provides).To(ContainElement(libcnb.BuildPlanProvide{Name: "jvm-application-package"}))
+		})
+	})
+
+	context("$BP_EXECUTABLE_JAR_MAIN_CLASS is set", func() {
 		it.Before(func() {
-			Expect(os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")).To(Succeed())
+			t.Setenv("BP_EXECUTABLE_JAR_MAIN_CLASS", "test.Main")
+		})
+
+		it("provides jvm-application-package without a manifest", func() {
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Plans[0].Provides).To(ContainElement(libcnb.BuildPlanProvide{Name: "jvm-application-package"}))
+		})
+	})
+
+	context("$BP_EXECUTABLE_JAR_SBOM is set", func() {
+		it("does not require syft with the native scanner", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_SBOM", "native")
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).NotTo(ContainElement(libcnb.BuildPlanRequire{Name: "syft"}))
+		})
+
+		it("does not require syft without an SBOM", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_SBOM", "none")
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).NotTo(ContainElement(libcnb.BuildPlanRequire{Name: "syft"}))
 		})
 
-		it.After(func() {
-			Expect(os.Unsetenv("BP_LIVE_RELOAD_ENABLED")).To(Succeed())
+		it("returns an error for an unknown scanner", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_SBOM", "trivy")
+
+			_, err := detect.Detect(ctx)
+			Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_SBOM must be syft, native or none")))
+		})
+	})
+
+	context("$BP_LIVE_RELOAD_ENABLED is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
 		})
 
 		it("requires watchexec", func() {
@@ -183,5 +243,20 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 				},
 			}))
 		})
+
+		it("does not require watchexec with the built-in watcher", func() {
+			t.Setenv("BP_LIVE_RELOAD_WATCHER", "builtin")
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).NotTo(ContainElement(libcnb.BuildPlanRequire{Name: "watchexec"}))
+		})
+
+		it("returns an error for an unknown watcher", func() {
+			t.Setenv("BP_LIVE_RELOAD_WATCHER", "inotifywait")
+
+			_, err := detect.Detect(ctx)
+			Expect(err).To(MatchError(ContainSubstring("$BP_LIVE_RELOAD_WATCHER must be watchexec or builtin")))
+		})
 	})
 }
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak"
+)
+
+var jfrDuration = regexp.MustCompile(`^[0-9]+(ns|us|ms|s|m|h|d)$`)
+
+// Profile is the $BP_EXECUTABLE_JAR_PROFILE_* configuration of the Java Flight Recorder used by the profile process.
+type Profile struct {
+	// Duration is the length of the recording, such as 60s, or empty to record until the JVM exits.
+	Duration string
+
+	// Settings is the name of a built in settings file, default or profile, or the path of a .jfc file.
+	Settings string
+
+	// OutputDirectory is the directory the recording is written to when the recording ends or the JVM exits.
+	OutputDirectory string
+}
+
+// NewProfile reads the profile configuration, returning an error for invalid values.
+func NewProfile(cr libpak.ConfigurationResolver) (Profile, error) {
+	p := Profile{Settings: "profile", OutputDirectory: "/tmp"}
+
+	if s, _ := cr.Resolve("BP_EXECUTABLE_JAR_PROFILE_DURATION"); s != "" {
+		if !jfrDuration.MatchString(s) {
+			return Profile{}, fmt.Errorf("$BP_EXECUTABLE_JAR_PROFILE_DURATION must be a duration such as 60s, 5m or 1h, not %q", s)
+		}
+		p.Duration = s
+	}
+
+	for _, d := range []struct {
+		name  string
+		value *string
+	}{
+		{"BP_EXECUTABLE_JAR_PROFILE_SETTINGS", &p.Settings},
+		{"BP_EXECUTABLE_JAR_PROFILE_OUTPUT_DIRECTORY", &p.OutputDirectory},
+	} {
+		s, _ := cr.Resolve(d.name)
+		if s == "" {
+			continue
+		}
+		if strings.ContainsAny(s, ", \t") {
+			return Profile{}, fmt.Errorf("$%s must not contain commas or whitespace, not %q", d.name, s)
+		}
+		*d.value = s
+	}
+
+	return p, nil
+}
+
+// Arguments returns the JVM arguments that start the recording.
+func (p Profile) Arguments() []string {
+	options := []string{
+		"dumponexit=true",
+		fmt.Sprintf("filename=%s", path.Join(p.OutputDirectory, "recording.jfr")),
+		fmt.Sprintf("settings=%s", p.Settings),
+	}
+	if p.Duration != "" {
+		options = append(options, fmt.Sprintf("duration=%s", p.Duration))
+	}
+
+	return []string{fmt.Sprintf("-XX:StartFlightRecording=%s", strings.Join(options, ","))}
+}
+
+// JMX is the $BP_EXECUTABLE_JAR_JMX_* configuration of the remote JMX connector used by the jmx process.
+type JMX struct {
+	// Port is the port of both the JMX registry and the RMI server, so that a single port can be forwarded.
+	Port int
+}
+
+// NewJMX reads the JMX configuration, returning an error for invalid values.
+func NewJMX(cr libpak.ConfigurationResolver) (JMX, error) {
+	j := JMX{Port: 5000}
+
+	if s, _ := cr.Resolve("BP_EXECUTABLE_JAR_JMX_PORT"); s != "" {
+		p, err := strconv.Atoi(s)
+		if err != nil || p < 1 || p > 65535 {
+			return JMX{}, fmt.Errorf("$BP_EXECUTABLE_JAR_JMX_PORT must be a port number, not %q", s)
+		}
+		j.Port = p
+	}
+
+	return j, nil
+}
+
+// Arguments returns the JVM arguments that enable the remote JMX connector. It is neither authenticated nor
+// encrypted, so it binds to and is advertised on 127.0.0.1 only and is meant to be reached through a forwarded port.
+func (j JMX) Arguments() []string {
+	return []string{
+		"-Djava.rmi.server.hostname=127.0.0.1",
+		"-Dcom.sun.management.jmxremote.host=127.0.0.1",
+		"-Dcom.sun.management.jmxremote.authenticate=false",
+		"-Dcom.sun.management.jmxremote.ssl=false",
+		fmt.Sprintf("-Dcom.sun.management.jmxremote.port=%d", j.Port),
+		fmt.Sprintf("-Dcom.sun.management.jmxremote.rmi.port=%d", j.Port),
+	}
+}
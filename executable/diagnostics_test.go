@@ -0,0 +1,105 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testDiagnostics(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr libpak.ConfigurationResolver
+	)
+
+	context("Profile", func() {
+		it("records until the JVM exits by default", func() {
+			p, err := executable.NewProfile(cr)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(p.Arguments()).To(Equal([]string{
+				"-XX:StartFlightRecording=dumponexit=true,filename=/tmp/recording.jfr,settings=profile",
+			}))
+		})
+
+		it("uses the configuration", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_PROFILE_DURATION", "5m")
+			t.Setenv("BP_EXECUTABLE_JAR_PROFILE_SETTINGS", "default")
+			t.Setenv("BP_EXECUTABLE_JAR_PROFILE_OUTPUT_DIRECTORY", "/recordings")
+
+			p, err := executable.NewProfile(cr)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(p.Arguments()).To(Equal([]string{
+				"-XX:StartFlightRecording=dumponexit=true,filename=/recordings/recording.jfr,settings=default,duration=5m",
+			}))
+		})
+
+		it("fails for an invalid duration", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_PROFILE_DURATION", "5 minutes")
+
+			_, err := executable.NewProfile(cr)
+			Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_PROFILE_DURATION must be a duration")))
+		})
+
+		it("fails for settings that would add options", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_PROFILE_SETTINGS", "default,disk=false")
+
+			_, err := executable.NewProfile(cr)
+			Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_PROFILE_SETTINGS must not contain commas")))
+		})
+	})
+
+	context("JMX", func() {
+		it("uses a single port", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_JMX_PORT", "9999")
+
+			j, err := executable.NewJMX(cr)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(j.Arguments()).To(Equal([]string{
+				"-Djava.rmi.server.hostname=127.0.0.1",
+				"-Dcom.sun.management.jmxremote.host=127.0.0.1",
+				"-Dcom.sun.management.jmxremote.authenticate=false",
+				"-Dcom.sun.management.jmxremote.ssl=false",
+				"-Dcom.sun.management.jmxremote.port=9999",
+				"-Dcom.sun.management.jmxremote.rmi.port=9999",
+			}))
+		})
+
+		it("binds the connector to loopback only", func() {
+			j, err := executable.NewJMX(cr)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(j.Arguments()).To(ContainElement("-Dcom.sun.management.jmxremote.host=127.0.0.1"))
+		})
+
+		it("fails for an invalid port", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_JMX_PORT", "jmx")
+
+			_, err := executable.NewJMX(cr)
+			Expect(err).To(MatchError(ContainSubstring("$BP_EXECUTABLE_JAR_JMX_PORT must be a port number")))
+		})
+	})
+}
@@ -17,16 +17,17 @@
 package executable
 
 import (
+	"archive/zip"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
 
 	"github.com/magiconair/properties"
-	"github.com/paketo-buildpacks/libjvm"
-
-	"github.com/paketo-buildpacks/executable-jar/v6/internal/fsutil"
 )
 
 type ExecutableJAR struct {
@@ -35,95 +36,157 @@ type ExecutableJAR struct {
 	Properties  *properties.Properties
 	Executable  bool
 	ExplodedJAR bool
+	Locator     string
 }
 
+// LoadExecutableJAR searches the application directory at appPath for an executable JAR. It is a thin wrapper around
+// LoadExecutableJARFS that translates the returned path back into a filesystem path. If there is no executable JAR, a
+// zero value and no error are returned, use FindExecutableJAR for ErrNoExecutableJAR.
 func LoadExecutableJAR(appPath string, executableJarGlob string) (ExecutableJAR, error) {
-	_, err := os.Stat(filepath.Join(appPath, "META-INF", "MANIFEST.MF"))
-	if err != nil && !os.IsNotExist(err) {
-		return ExecutableJAR{}, fmt.Errorf("unable to read manifest.mf\n%w", err)
+	execJar, err := loadExecutableJAR(appPath, LocatorContext{ExecutableJARGlob: executableJarGlob}, DefaultLocators())
+	if errors.Is(err, ErrNoExecutableJAR) {
+		return ExecutableJAR{}, nil
 	}
+	return execJar, err
+}
 
-	explodedJAR := !(err != nil && os.IsNotExist(err))
-	var props *properties.Properties
-	var jarPath = appPath
+// LoadExecutableJARFS searches fsys for an executable JAR using the DefaultLocators. By default, if the root of fsys
+// contains a META-INF/MANIFEST.MF it is treated as an exploded JAR, otherwise fsys is searched breadth-first for the
+// first JAR file with a Main-Class, starting with any files matching executableJarGlob. The returned Path is
+// slash-separated and relative to fsys. If there is no executable JAR, a zero value and no error are returned, see
+// FindExecutableJAR for the other errors.
+func LoadExecutableJARFS(fsys fs.FS, executableJarGlob string) (ExecutableJAR, error) {
+	execJar, err := FindExecutableJAR(LocatorContext{FS: fsys, ExecutableJARGlob: executableJarGlob}, DefaultLocators())
+	if errors.Is(err, ErrNoExecutableJAR) {
+		return ExecutableJAR{}, nil
+	}
+	return execJar, err
+}
 
-	if explodedJAR {
-		props, err = libjvm.NewManifest(appPath)
-		if err != nil {
-			return ExecutableJAR{}, fmt.Errorf("unable to parse manifest\n%w", err)
-		}
+func loadExecutableJAR(appPath string, context LocatorContext, locators []Locator) (ExecutableJAR, error) {
+	context.FS = os.DirFS(appPath)
+	if locators == nil {
+		locators = DefaultLocators()
+	}
+
+	execJar, err := FindExecutableJAR(context, locators)
+	if err != nil {
+		return ExecutableJAR{}, err
+	}
+
+	if execJar.ExplodedJAR {
+		execJar.Path = appPath
 	} else {
-		jarPath, props, err = findExecutableJAR(appPath, executableJarGlob)
-		if err != nil {
-			return ExecutableJAR{}, fmt.Errorf("unable to parse manifest\n%w", err)
-		}
+		execJar.Path = filepath.Join(appPath, filepath.FromSlash(execJar.Path))
 	}
 
-	if mc, ok := props.Get("Main-Class"); ok {
-		return ExecutableJAR{
-			MainClass:   mc,
-			Properties:  props,
-			Path:        jarPath,
-			Executable:  ok,
-			ExplodedJAR: appPath == jarPath,
-		}, nil
+	return execJar, nil
+}
+
+// NewManifestFS reads the <dir>/META-INF/MANIFEST.MF file within fsys if it exists, normalizing it into the standard
+// properties form, see parseManifest.
+func NewManifestFS(fsys fs.FS, dir string) (*properties.Properties, error) {
+	file := path.Join(dir, "META-INF", "MANIFEST.MF")
+
+	b, err := fs.ReadFile(fsys, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return properties.NewProperties(), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
 	}
 
-	return ExecutableJAR{}, nil
+	return parseManifest(b, file)
 }
 
-func findExecutableJAR(appPath, executableJarGlob string) (string, *properties.Properties, error) {
-	props := &properties.Properties{}
-	jarPath := ""
-	stopWalk := errors.New("stop walking")
-
-	w := func(root string, fn filepath.WalkFunc) error {
-		if executableJarGlob != "" {
-			files, _ := filepath.Glob(filepath.Join(appPath, executableJarGlob))
-			for _, f := range files {
-				fi, err := os.Lstat(f)
-				err = fn(f, fi, err)
-				if err != nil {
-					return err
-				}
-			}
-		}
-		return fsutil.Walk(root, fn)
+// NewManifestFromJARFS reads the META-INF/MANIFEST.MF from the JAR file at name within fsys if it exists,
+// normalizing it into the standard properties form, see parseManifest.
+func NewManifestFromJARFS(fsys fs.FS, name string) (*properties.Properties, error) {
+	jarFile, err := openJAR(fsys, name)
+	if isZipError(err) {
+		return nil, fmt.Errorf("unable to read file %s\n%w", name, invalidManifest(err))
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read file %s\n%w", name, err)
 	}
+	defer jarFile.Close()
+
+	b, err := fs.ReadFile(jarFile.Reader, "META-INF/MANIFEST.MF")
+	if errors.Is(err, fs.ErrNotExist) {
+		return properties.NewProperties(), nil
+	} else if isZipError(err) {
+		return nil, fmt.Errorf("unable to read MANIFEST.MF in %s\n%w", name, invalidManifest(err))
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read MANIFEST.MF in %s\n%w", name, err)
+	}
+
+	return parseManifest(b, name)
+}
 
-	err := w(appPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// make sure it is a file
-		if info.IsDir() {
-			return nil
-		}
-
-		// make sure it is a JAR file
-		if !strings.HasSuffix(path, ".jar") {
-			return nil
-		}
-
-		// get the MANIFEST of the JAR file
-		props, err = libjvm.NewManifestFromJAR(path)
-		if err != nil {
-			return fmt.Errorf("unable to load manifest\n%w", err)
-		}
-
-		// we take it if it has a Main-Class
-		if _, ok := props.Get("Main-Class"); ok {
-			jarPath = path
-			return stopWalk
-		}
-
-		return nil
-	})
-
-	if err != nil && !errors.Is(err, stopWalk) {
-		return "", nil, err
+// parseManifest parses the contents of the manifest read from source into the standard properties form, in the same
+// way as libjvm.NewManifest: line endings are normalized and continuation lines, which start with a space, are joined
+// to the line they continue.
+func parseManifest(b []byte, source string) (*properties.Properties, error) {
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+	b = bytes.ReplaceAll(b, []byte("\r"), []byte("\n"))
+	b = bytes.ReplaceAll(b, []byte("\n "), []byte{})
+
+	manifest, err := properties.Load(b, properties.UTF8)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse MANIFEST.MF in %s\n%w", source, invalidManifest(err))
 	}
 
-	return jarPath, props, nil
+	return manifest, nil
+}
+
+type jarReader struct {
+	*zip.Reader
+	io.Closer
+}
+
+// openJAR opens the JAR file at name within fsys. Files that support random access are read in place, anything else
+// is buffered in memory.
+func openJAR(fsys fs.FS, name string) (jarReader, error) {
+	ra, size, c, err := openReaderAt(fsys, name)
+	if err != nil {
+		return jarReader{}, err
+	}
+
+	z, err := zip.NewReader(ra, size)
+	if err != nil {
+		c.Close()
+		return jarReader{}, err
+	}
+
+	return jarReader{Reader: z, Closer: c}, nil
+}
+
+// openReaderAt opens the file at name within fsys for random access, returning it, its size and what closes it. Files
+// that do not support random access are buffered in memory.
+func openReaderAt(fsys fs.FS, name string) (io.ReaderAt, int64, io.Closer, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, nil, err
+	}
+
+	if r, ok := f.(io.ReaderAt); ok {
+		return r, fi.Size(), f, nil
+	}
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return nil, 0, nil, err
+	}
+	return bytes.NewReader(b), int64(len(b)), f, nil
+}
+
+// isZipError reports whether err was caused by a malformed archive rather than by being unable to read it.
+func isZipError(err error) bool {
+	return errors.Is(err, zip.ErrFormat) || errors.Is(err, zip.ErrAlgorithm) || errors.Is(err, zip.ErrChecksum) ||
+		errors.Is(err, zip.ErrInsecurePath) || errors.Is(err, io.ErrUnexpectedEOF)
 }
@@ -18,13 +18,19 @@ package executable_test
 
 import (
 	"archive/zip"
+	"bytes"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
+	"github.com/magiconair/properties"
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/sclevine/spec"
 )
 
@@ -144,6 +150,28 @@ func testManifest(t *testing.T, context spec.G, it spec.S) {
 			Expect(ej.Properties.Map()).To(HaveKeyWithValue("Main-Class", "Foo2"))
 		})
 
+		it("loads props from an executable JAR behind a symlinked directory", func() {
+			dist := t.TempDir()
+			Expect(CreateJAR(filepath.Join(dist, "app.jar"), map[string]string{"Main-Class": "Foo"})).To(Succeed())
+			Expect(os.Symlink(dist, filepath.Join(appPath, "lib"))).To(Succeed())
+
+			ej, err := executable.LoadExecutableJAR(appPath, "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ej.MainClass).To(Equal("Foo"))
+			Expect(ej.Path).To(Equal(filepath.Join(appPath, "lib", "app.jar")))
+		})
+
+		it("skips broken symlinks", func() {
+			Expect(os.Symlink("missing.jar", filepath.Join(appPath, "a.jar"))).To(Succeed())
+			Expect(CreateJAR(filepath.Join(appPath, "b.jar"), map[string]string{"Main-Class": "Foo"})).To(Succeed())
+
+			ej, err := executable.LoadExecutableJAR(appPath, "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ej.Path).To(Equal(filepath.Join(appPath, "b.jar")))
+		})
+
 		it("skips non-executable JARs", func() {
 			Expect(CreateJAR(filepath.Join(appPath, "test-1.jar"), map[string]string{"foo": "bar"})).To(Succeed())
 			Expect(CreateJAR(filepath.Join(appPath, "test-2.jar"), map[string]string{"Main-Class": "Foo2"})).To(Succeed())
@@ -158,6 +186,199 @@ func testManifest(t *testing.T, context spec.G, it spec.S) {
 			Expect(ej.Properties.Map()).To(HaveKeyWithValue("Main-Class", "Foo2"))
 		})
 	})
+
+	context("fs.FS", func() {
+		it("loads an exploded JAR", func() {
+			fsys := fstest.MapFS{
+				"META-INF/MANIFEST.MF": {Data: []byte("Main-Class: Foo\nClass-Path: a.jar\n  b.jar")},
+			}
+
+			ej, err := executable.LoadExecutableJARFS(fsys, "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ej.MainClass).To(Equal("Foo"))
+			Expect(ej.Path).To(Equal("."))
+			Expect(ej.ExplodedJAR).To(BeTrue())
+			Expect(ej.Executable).To(BeTrue())
+			Expect(ej.Properties.Map()).To(HaveKeyWithValue("Class-Path", "a.jar b.jar"))
+		})
+
+		it("normalizes the line endings of a manifest", func() {
+			fsys := fstest.MapFS{
+				"META-INF/MANIFEST.MF": {Data: []byte("Main-Class: Foo\r\nClass-Path: a.jar\r\n  b.jar\rStart-Class: Bar\r\n")},
+			}
+
+			manifest, err := executable.NewManifestFS(fsys, "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Map()).To(Equal(map[string]string{
+				"Main-Class":  "Foo",
+				"Class-Path":  "a.jar b.jar",
+				"Start-Class": "Bar",
+			}))
+		})
+
+		it("returns empty properties for a missing manifest", func() {
+			fsys := fstest.MapFS{
+				"test-1.jar": {Data: JARBytes(t, nil)},
+			}
+
+			manifest, err := executable.NewManifestFS(fsys, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest).To(Equal(properties.NewProperties()))
+
+			manifest, err = executable.NewManifestFromJARFS(fsys, "test-1.jar")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest).To(Equal(properties.NewProperties()))
+		})
+
+		it("loads the first executable JAR found", func() {
+			fsys := fstest.MapFS{
+				"lib/a.jar":  {Data: JARBytes(t, map[string]string{"Main-Class": "Lib1"})},
+				"test-1.jar": {Data: JARBytes(t, map[string]string{"foo": "bar"})},
+				"test-2.jar": {Data: JARBytes(t, map[string]string{"Main-Class": "Foo2"})},
+			}
+
+			ej, err := executable.LoadExecutableJARFS(fsys, "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ej.MainClass).To(Equal("Foo2"))
+			Expect(ej.Path).To(Equal("test-2.jar"))
+			Expect(ej.ExplodedJAR).To(BeFalse())
+			Expect(ej.Executable).To(BeTrue())
+		})
+
+		it("loads the executable JAR specified by glob", func() {
+			fsys := fstest.MapFS{
+				"lib/a.jar":  {Data: JARBytes(t, map[string]string{"Main-Class": "Lib1"})},
+				"test-1.jar": {Data: JARBytes(t, map[string]string{"Main-Class": "Foo1"})},
+			}
+
+			ej, err := executable.LoadExecutableJARFS(fsys, "./lib/*.jar")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ej.MainClass).To(Equal("Lib1"))
+			Expect(ej.Path).To(Equal("lib/a.jar"))
+		})
+
+		it("reads JARs that do not support random access", func() {
+			fsys := sequentialFS{fstest.MapFS{
+				"test-1.jar": {Data: JARBytes(t, map[string]string{"Main-Class": "Foo1"})},
+			}}
+
+			ej, err := executable.LoadExecutableJARFS(fsys, "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ej.MainClass).To(Equal("Foo1"))
+			Expect(ej.Path).To(Equal("test-1.jar"))
+		})
+
+		it("returns an empty result if nothing is executable", func() {
+			ej, err := executable.LoadExecutableJARFS(fstest.MapFS{
+				"test-1.jar": {Data: JARBytes(t, nil)},
+			}, "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ej).To(Equal(executable.ExecutableJAR{}))
+		})
+
+		it("returns ErrNoExecutableJAR from FindExecutableJAR if nothing is executable", func() {
+			_, err := executable.FindExecutableJAR(executable.LocatorContext{FS: fstest.MapFS{
+				"test-1.jar": {Data: JARBytes(t, nil)},
+			}}, executable.DefaultLocators())
+
+			Expect(err).To(MatchError(executable.ErrNoExecutableJAR))
+		})
+
+		it("returns ErrInvalidManifest if a JAR ahead of the executable one is corrupt", func() {
+			_, err := executable.LoadExecutableJARFS(fstest.MapFS{
+				"test-1.jar": {Data: []byte("not a zip")},
+				"test-2.jar": {Data: JARBytes(t, map[string]string{"Main-Class": "Foo2"})},
+			}, "")
+
+			Expect(err).To(MatchError(executable.ErrInvalidManifest))
+			Expect(err).To(MatchError(ContainSubstring("test-1.jar")))
+		})
+
+		it("returns ErrInvalidManifest for an unparseable exploded manifest", func() {
+			_, err := executable.LoadExecutableJARFS(fstest.MapFS{
+				"META-INF/MANIFEST.MF": {Data: []byte("Main-Class: ${unterminated")},
+			}, "")
+
+			Expect(err).To(MatchError(executable.ErrInvalidManifest))
+		})
+
+		context("glob matching more than one executable JAR", func() {
+			var fsys fstest.MapFS
+
+			it.Before(func() {
+				fsys = fstest.MapFS{
+					"test-1.jar": {Data: JARBytes(t, map[string]string{"Main-Class": "Foo1"})},
+					"test-2.jar": {Data: JARBytes(t, map[string]string{"foo": "bar"})},
+					"test-3.jar": {Data: JARBytes(t, map[string]string{"Main-Class": "Foo3"})},
+				}
+			})
+
+			it("uses the first match and warns of the others", func() {
+				b := &bytes.Buffer{}
+
+				ej, err := executable.FindExecutableJAR(executable.LocatorContext{
+					FS:                fsys,
+					ExecutableJARGlob: "test-*.jar",
+					Logger:            bard.NewLogger(b),
+				}, executable.DefaultLocators())
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ej.Path).To(Equal("test-1.jar"))
+				Expect(b.String()).To(ContainSubstring("WARNING: test-1.jar and test-3.jar both match $BP_EXECUTABLE_JAR_LOCATION \"test-*.jar\", using test-1.jar"))
+			})
+
+			it("returns ErrAmbiguousJAR with a strict location", func() {
+				_, err := executable.FindExecutableJAR(executable.LocatorContext{
+					FS:                fsys,
+					ExecutableJARGlob: "test-*.jar",
+					StrictLocation:    true,
+				}, executable.DefaultLocators())
+
+				Expect(err).To(MatchError(executable.ErrAmbiguousJAR))
+				Expect(err).To(MatchError(ContainSubstring("test-1.jar and test-3.jar")))
+			})
+		})
+
+		it("ignores non-executable JARs matching the glob", func() {
+			ej, err := executable.LoadExecutableJARFS(fstest.MapFS{
+				"test-1.jar": {Data: JARBytes(t, map[string]string{"foo": "bar"})},
+				"test-2.jar": {Data: JARBytes(t, map[string]string{"Main-Class": "Foo2"})},
+				"lib/a.jar":  {Data: JARBytes(t, map[string]string{"Main-Class": "Lib1"})},
+			}, "test-*.jar")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ej.Path).To(Equal("test-2.jar"))
+		})
+	})
+}
+
+// sequentialFS hides io.ReaderAt from the files it opens, like a tar stream would.
+type sequentialFS struct {
+	fstest.MapFS
+}
+
+func (s sequentialFS) Open(name string) (fs.File, error) {
+	f, err := s.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ fs.File }{f}, nil
+}
+
+func JARBytes(t *testing.T, props map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := writeJAR(&buf, props); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
 }
 
 func CreateJAR(fileName string, props map[string]string) error {
@@ -167,7 +388,11 @@ func CreateJAR(fileName string, props map[string]string) error {
 	}
 	defer archive.Close()
 
-	zipWriter := zip.NewWriter(archive)
+	return writeJAR(archive, props)
+}
+
+func writeJAR(w io.Writer, props map[string]string) error {
+	zipWriter := zip.NewWriter(w)
 
 	if props != nil {
 		manifestWriter, err := zipWriter.Create("META-INF/MANIFEST.MF")
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ExplodeExecutableJAR extracts execJar into the application at appPath and removes the JAR file, so that its classes
+// can be changed individually. The returned ExecutableJAR is exploded, with the relative entries of its Class-Path
+// rebased onto appPath.
+func ExplodeExecutableJAR(appPath string, execJar ExecutableJAR) (ExecutableJAR, error) {
+	if execJar.ExplodedJAR {
+		return execJar, nil
+	}
+
+	if err := extractJAR(execJar.Path, appPath); err != nil {
+		return ExecutableJAR{}, err
+	}
+
+	if err := os.Remove(execJar.Path); err != nil {
+		return ExecutableJAR{}, fmt.Errorf("unable to remove %s\n%w", execJar.Path, err)
+	}
+
+	props, err := NewManifestFS(os.DirFS(appPath), ".")
+	if err != nil {
+		return ExecutableJAR{}, fmt.Errorf("unable to read manifest of exploded %s\n%w", execJar.Path, err)
+	}
+
+	if s, ok := props.Get("Class-Path"); ok {
+		dir, err := filepath.Rel(appPath, filepath.Dir(execJar.Path))
+		if err != nil {
+			return ExecutableJAR{}, fmt.Errorf("unable to relativize %s\n%w", execJar.Path, err)
+		}
+
+		var cp []string
+		for _, e := range strings.Fields(s) {
+			if !path.IsAbs(e) && !strings.Contains(e, ":") {
+				e = path.Join(filepath.ToSlash(dir), e)
+			}
+			cp = append(cp, e)
+		}
+		props.Set("Class-Path", strings.Join(cp, " "))
+	}
+
+	execJar.Path = appPath
+	execJar.ExplodedJAR = true
+	execJar.Properties = props
+	return execJar, nil
+}
+
+// extractJAR extracts the JAR file at jar into destination, rejecting entries that would be written outside it.
+func extractJAR(jar string, destination string) error {
+	z, err := zip.OpenReader(jar)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", jar, err)
+	}
+	defer z.Close()
+
+	for _, f := range z.File {
+		if !filepath.IsLocal(filepath.FromSlash(f.Name)) {
+			return fmt.Errorf("unable to extract %s from %s: path is outside the destination", f.Name, jar)
+		}
+
+		target := filepath.Join(destination, filepath.FromSlash(f.Name))
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("unable to create directory %s\n%w", target, err)
+			}
+			continue
+		}
+
+		if err := extractFile(f, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractFile(f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(target), err)
+	}
+
+	mode := f.Mode().Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+
+	in, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", f.Name, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", target, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("unable to extract %s to %s\n%w", f.Name, target, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+// CreateZIP writes a zip file containing entries, mapping names to contents. Names ending in / are directories.
+func CreateZIP(t *testing.T, file string, entries map[string]string) {
+	t.Helper()
+
+	out, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	var names []string
+	for n := range entries {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	z := zip.NewWriter(out)
+	for _, n := range names {
+		w, err := z.Create(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entries[n])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testExplode(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		path = t.TempDir()
+		Expect(os.MkdirAll(filepath.Join(path, "target"), 0755)).To(Succeed())
+	})
+
+	it("explodes the JAR into the application", func() {
+		CreateZIP(t, filepath.Join(path, "target", "app.jar"), map[string]string{
+			"META-INF/":            "",
+			"META-INF/MANIFEST.MF": "Main-Class: test-main-class\nClass-Path: lib/a.jar /opt/b.jar file:/opt/c.jar\n",
+			"com/example/A.class":  "test-class",
+		})
+
+		execJar, err := executable.LoadExecutableJAR(path, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		execJar, err = executable.ExplodeExecutableJAR(path, execJar)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(execJar.ExplodedJAR).To(BeTrue())
+		Expect(execJar.Path).To(Equal(path))
+		Expect(execJar.MainClass).To(Equal("test-main-class"))
+		Expect(execJar.Properties.GetString("Class-Path", "")).To(Equal("target/lib/a.jar /opt/b.jar file:/opt/c.jar"))
+
+		Expect(filepath.Join(path, "target", "app.jar")).NotTo(BeAnExistingFile())
+		Expect(os.ReadFile(filepath.Join(path, "com", "example", "A.class"))).To(Equal([]byte("test-class")))
+
+		execJar, err = executable.LoadExecutableJAR(path, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(execJar.ExplodedJAR).To(BeTrue())
+	})
+
+	it("leaves an exploded JAR unchanged", func() {
+		execJar := executable.ExecutableJAR{Path: path, ExplodedJAR: true, MainClass: "test-main-class"}
+		Expect(executable.ExplodeExecutableJAR(path, execJar)).To(Equal(execJar))
+	})
+
+	it("rejects entries outside the application", func() {
+		CreateZIP(t, filepath.Join(path, "app.jar"), map[string]string{
+			"META-INF/MANIFEST.MF": "Main-Class: test-main-class\n",
+			"../evil.class":        "test-class",
+		})
+
+		_, err := executable.ExplodeExecutableJAR(path, executable.ExecutableJAR{
+			Path:      filepath.Join(path, "app.jar"),
+			MainClass: "test-main-class",
+		})
+		Expect(err).To(MatchError(ContainSubstring("path is outside the destination")))
+		Expect(filepath.Join(filepath.Dir(path), "evil.class")).NotTo(BeAnExistingFile())
+	})
+}
@@ -25,9 +25,32 @@ import (
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("executable", spec.Report(report.Terminal{}))
+	suite("ApplicationType", testApplicationType)
 	suite("Build", testBuild)
+	suite("Candidates", testCandidates)
 	suite("ClassPath", testClassPath)
+	suite("Configuration", testConfiguration)
+	suite("DangerousClasses", testDangerousClasses)
+	suite("Debug", testDebug)
 	suite("Detect", testDetect)
+	suite("Diagnostics", testDiagnostics)
+	suite("Explode", testExplode)
+	suite("Inspect", testInspect)
+	suite("Integrity", testIntegrity)
+	suite("JARSignatures", testJARSignatures)
+	suite("Licenses", testLicenses)
+	suite("LiveReload", testLiveReload)
+	suite("Locator", testLocator)
 	suite("Manifest", testManifest)
+	suite("MavenVersion", testMavenVersion)
+	suite("Metadata", testMetadata)
+	suite("Packages", testPackages)
+	suite("Permissions", testPermissions)
+	suite("Processes", testProcesses)
+	suite("SBOM", testSBOM)
+	suite("SBOMCache", testSBOMCache)
+	suite("Syft", testSyft)
+	suite("Vulnerabilities", testVulnerabilities)
+	suite("Watcher", testWatcher)
 	suite.Run(t)
 }
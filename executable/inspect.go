@@ -0,0 +1,365 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Inspection is what is found in the archives within the application and on its runtime classpath, see Inspect.
+type Inspection struct {
+
+	// Problems are the integrity problems of the archives on the classpath: archives that are truncated or corrupt,
+	// whose entries do not match their CRC or size, whose entries overlap, and entries that are duplicated or whose
+	// names would be extracted outside of the directory they are extracted to. Unlike reading the manifest, every
+	// entry is read.
+	Problems []IntegrityProblem
+
+	// DangerousClasses are the dangerous classes in the archives and at the root of exploded JARs, sorted by location.
+	DangerousClasses []DangerousClass
+
+	// Packages are the libraries sorted by location, see Inventory, or nil if they were not looked for.
+	Packages []Package
+}
+
+// Inspect reads each archive within scanDir and on the runtime classPath of the application once and returns the
+// integrity problems of the archives on classPath, the dangerous classes of signatures and, if inventory is true, the
+// libraries as Inventory finds them, keeping the packages of each archive in cache. Archives that are within more than
+// one classPath entry are read once, and so are the archives nested within archives.
+func Inspect(scanDir string, classPath []string, signatures []ClassSignature, inventory bool, cache *PackageCache) (Inspection, error) {
+	i := newInspector()
+	i.integrity, i.signatures, i.inventory, i.cache = true, signatures, inventory, cache
+
+	if err := i.walk(scanDir, classPath); err != nil {
+		return Inspection{}, fmt.Errorf("unable to inspect the archives of the application\n%w", err)
+	}
+
+	inspection := Inspection{Problems: i.problems, DangerousClasses: i.dangerousClasses()}
+	if inventory {
+		inspection.Packages = dedupePackages(i.packages)
+	}
+	return inspection, nil
+}
+
+// Reinventory returns packages with the libraries of the archives at locations, which have changed since packages were
+// found, found again. It is used once dangerous classes have been removed from archives.
+func Reinventory(scanDir string, packages []Package, locations []string, cache *PackageCache) ([]Package, error) {
+	changed := map[string]bool{}
+	for _, l := range locations {
+		if l, _, _ = strings.Cut(l, ":"); isArchive(l) {
+			changed[l] = true
+		}
+	}
+
+	var kept []Package
+	for _, p := range packages {
+		if l, _, _ := strings.Cut(p.Location, ":"); !changed[l] {
+			kept = append(kept, p)
+		}
+	}
+
+	i := newInspector()
+	i.inventory, i.cache = true, cache
+
+	for l := range changed {
+		file := filepath.FromSlash(l)
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(scanDir, file)
+		}
+
+		if err := i.archive(os.DirFS(filepath.Dir(file)), filepath.Base(file), l, file, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return dedupePackages(append(kept, i.packages...)), nil
+}
+
+// inspector reads archives and the archives nested within them once for each of the enabled checks.
+type inspector struct {
+	// integrity enables checking the integrity of the archives on the classpath.
+	integrity bool
+
+	// signatures are the classes to look for, none to not look for dangerous classes.
+	signatures []ClassSignature
+
+	// inventory enables finding the libraries, with the packages of unchanged archives read from cache if it is not nil.
+	inventory bool
+	cache     *PackageCache
+
+	problems  []IntegrityProblem
+	dangerous []DangerousClass
+	packages  []Package
+
+	read map[string]bool
+}
+
+func newInspector() *inspector {
+	return &inspector{read: map[string]bool{}}
+}
+
+// walk inspects the exploded JAR and the archives within scanDir, if it is not empty, and the entries of classPath
+// outside of scanDir: archives, and exploded JARs and the archives within them. Archives that are or are within an
+// entry of classPath are on the classpath. The locations of the entries within scanDir are relative to it, and those
+// of the entries outside of it are absolute.
+func (i *inspector) walk(scanDir string, classPath []string) error {
+	var dirs []string
+	for _, c := range classPath {
+		if fi, err := os.Stat(c); err == nil && fi.IsDir() {
+			dirs = append(dirs, c)
+		}
+	}
+
+	onClassPath := func(file string) bool {
+		for _, c := range classPath {
+			if filepath.Clean(c) == filepath.Clean(file) {
+				return true
+			}
+		}
+		for _, d := range dirs {
+			if rel, err := filepath.Rel(d, file); err == nil && filepath.IsLocal(rel) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if scanDir != "" {
+		if err := i.directory(os.DirFS(scanDir), scanDir, "", onClassPath); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range classPath {
+		if rel, err := filepath.Rel(scanDir, c); scanDir != "" && err == nil && filepath.IsLocal(rel) {
+			// already inspected within scanDir
+			continue
+		}
+
+		fi, err := os.Stat(c)
+		if errors.Is(err, fs.ErrNotExist) {
+			// the JVM ignores missing classpath entries
+			continue
+		} else if err != nil {
+			return fmt.Errorf("unable to stat %s\n%w", c, err)
+		}
+
+		if fi.IsDir() {
+			err = i.directory(os.DirFS(c), c, filepath.ToSlash(c), onClassPath)
+		} else if isArchive(c) && !i.read[filepath.Clean(c)] {
+			i.read[filepath.Clean(c)] = true
+			err = i.archive(os.DirFS(filepath.Dir(c)), filepath.Base(c), filepath.ToSlash(c), c, true)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// directory inspects the exploded JAR at the root of fsys, the directory dir, and the archives within it. The
+// locations within it are prefixed by prefix, and archives are on the classpath if onClassPath, if any, reports so.
+func (i *inspector) directory(fsys fs.FS, dir string, prefix string, onClassPath func(string) bool) error {
+	location := "."
+	if prefix != "" {
+		location = prefix
+	}
+
+	if len(i.signatures) > 0 {
+		i.dangerous = append(i.dangerous, dangerousClassesInDirectory(fsys, location, i.signatures)...)
+	}
+
+	if i.inventory {
+		p, err := packagesInDirectory(fsys)
+		if err != nil {
+			return err
+		}
+		for j := range p {
+			p[j].Location = location
+		}
+		i.packages = append(i.packages, p...)
+	}
+
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !isArchive(name) {
+			return nil
+		}
+
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if i.read[file] {
+			return nil
+		}
+		i.read[file] = true
+
+		location := name
+		if prefix != "" {
+			location = path.Join(prefix, name)
+		}
+
+		return i.archive(fsys, name, location, file, onClassPath != nil && onClassPath(file))
+	}); err != nil {
+		return fmt.Errorf("unable to inspect the archives in %s\n%w", dir, err)
+	}
+
+	return nil
+}
+
+// archive inspects the archive name within fsys at location, the file file, checking its integrity if onClassPath. It
+// is read in place rather than into memory where possible. Its packages are read from and stored in the cache, unless
+// it has integrity problems.
+func (i *inspector) archive(fsys fs.FS, name string, location string, file string, onClassPath bool) error {
+	integrity := i.integrity && onClassPath
+	if !integrity && !i.inventory && len(i.signatures) == 0 {
+		return nil
+	}
+
+	ra, size, c, err := openReaderAt(fsys, name)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer c.Close()
+
+	var sum string
+	inventory := i.inventory
+	if inventory {
+		h := sha256.New()
+		if _, err := io.Copy(h, io.NewSectionReader(ra, 0, size)); err != nil {
+			return fmt.Errorf("unable to read %s\n%w", file, err)
+		}
+		sum = fmt.Sprintf("%x", h.Sum(nil))
+
+		if i.cache != nil {
+			if p, ok := i.cache.get(sum, location); ok {
+				i.packages = append(i.packages, p...)
+				inventory = false
+			}
+		}
+	}
+
+	if !integrity && !inventory && len(i.signatures) == 0 {
+		return nil
+	}
+
+	problems := len(i.problems)
+	p, err := i.nested(ra, size, location, filepath.ToSlash(file), sum, integrity, inventory)
+	if err != nil {
+		return err
+	}
+	if !inventory {
+		return nil
+	}
+
+	i.packages = append(i.packages, p...)
+	if i.cache != nil && len(i.problems) == problems {
+		if err := i.cache.put(sum, location, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nested inspects the archive ra of size bytes at location with checksum sum, whose integrity problems are at
+// problemLocation, and the archives nested within it, and returns their packages if inventory. Each nested archive is
+// inspected as soon as it has been read and released before the next one is read.
+func (i *inspector) nested(ra io.ReaderAt, size int64, location string, problemLocation string, sum string, integrity bool, inventory bool) ([]Package, error) {
+	z, err := zip.NewReader(ra, size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		if integrity {
+			i.problems = append(i.problems, IntegrityProblem{Location: problemLocation, Problem: fmt.Sprintf("is not a valid archive: %s", err)})
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to open %s\n%w", location, err)
+	}
+
+	if len(i.signatures) > 0 {
+		d, err := dangerousClassesInArchive(z, location, i.signatures)
+		if err != nil {
+			return nil, err
+		}
+		i.dangerous = append(i.dangerous, d...)
+	}
+
+	var packages []Package
+	if inventory {
+		if packages, err = packagesInArchive(z, location, sum); err != nil {
+			return nil, err
+		}
+	}
+
+	inspect := func(name string, c []byte) error {
+		var sum string
+		if inventory {
+			sum = fmt.Sprintf("%x", sha256.Sum256(c))
+		}
+
+		p, err := i.nested(bytes.NewReader(c), int64(len(c)), location+":"+name, problemLocation+":"+name, sum, integrity, inventory)
+		if err != nil {
+			return err
+		}
+		packages = append(packages, p...)
+		return nil
+	}
+
+	if integrity {
+		if err := checkArchiveIntegrity(z, size, problemLocation, func(p IntegrityProblem) {
+			i.problems = append(i.problems, p)
+		}, inspect); err != nil {
+			return nil, err
+		}
+		return packages, nil
+	}
+
+	for _, f := range z.File {
+		if !isArchive(f.Name) || f.FileInfo().IsDir() {
+			continue
+		}
+
+		c, err := readEntry(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s in %s\n%w", f.Name, location, err)
+		}
+		if err := inspect(f.Name, c); err != nil {
+			return nil, err
+		}
+	}
+
+	return packages, nil
+}
+
+// dangerousClasses returns the dangerous classes found, sorted by location.
+func (i *inspector) dangerousClasses() []DangerousClass {
+	sort.SliceStable(i.dangerous, func(a, b int) bool {
+		return i.dangerous[a].Location < i.dangerous[b].Location
+	})
+	return i.dangerous
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testInspect(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath    string
+		external   string
+		classPath  []string
+		signatures []executable.ClassSignature
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+		external = t.TempDir()
+
+		var err error
+		signatures, err = executable.LoadClassSignatures(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(appPath, "lib"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "lib", "log4j-core-2.14.1.jar"), []byte(log4jCore(t, "2.14.1")), 0644)).To(Succeed())
+
+		corrupt := filepath.Join(appPath, "lib", "corrupt-1.0.jar")
+		b := createStoredZIP(t, corrupt, [][2]string{{"com/example/Library.class", "original"}})
+		Expect(os.WriteFile(corrupt, bytes.Replace(b, []byte("original"), []byte("modified"), 1), 0644)).To(Succeed())
+
+		nested := filepath.Join(t.TempDir(), "nested.jar")
+		CreateZIP(t, nested, map[string]string{
+			"META-INF/maven/org.example/nested/pom.properties": "groupId=org.example\nartifactId=nested\nversion=1.0\n",
+		})
+		b, err = os.ReadFile(nested)
+		Expect(err).NotTo(HaveOccurred())
+		CreateZIP(t, filepath.Join(external, "shared-2.0.jar"), map[string]string{
+			"BOOT-INF/lib/nested-1.0.jar": string(b),
+		})
+
+		classPath = []string{appPath, filepath.Join(external, "shared-2.0.jar")}
+	})
+
+	it("finds the problems, dangerous classes and libraries of all archives", func() {
+		inspection, err := executable.Inspect(appPath, classPath, signatures, true, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inspection.Problems).To(ConsistOf(executable.IntegrityProblem{
+			Location: filepath.ToSlash(filepath.Join(appPath, "lib", "corrupt-1.0.jar")),
+			Entry:    "com/example/Library.class",
+			Problem:  "cannot be read: zip: checksum error",
+		}))
+
+		Expect(inspection.DangerousClasses).To(Equal([]executable.DangerousClass{
+			{Signature: signatures[0], Location: "lib/log4j-core-2.14.1.jar", Version: "2.14.1"},
+		}))
+
+		packages, err := executable.Inventory(appPath, classPath, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inspection.Packages).To(HaveLen(4))
+		Expect(inspection.Packages).To(Equal(packages))
+		Expect(inspection.Packages).To(ContainElement(HaveField("Location", filepath.ToSlash(filepath.Join(external, "shared-2.0.jar"))+":BOOT-INF/lib/nested-1.0.jar")))
+	})
+
+	it("only checks the integrity of archives on the classpath", func() {
+		inspection, err := executable.Inspect(appPath, []string{filepath.Join(appPath, "lib", "log4j-core-2.14.1.jar")}, signatures, true, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inspection.Problems).To(BeEmpty())
+		Expect(inspection.DangerousClasses).To(HaveLen(1))
+		Expect(inspection.Packages).To(ContainElement(HaveField("Location", "lib/corrupt-1.0.jar")))
+	})
+
+	it("does not find libraries unless asked to", func() {
+		cache := executable.NewPackageCache(t.TempDir())
+
+		inspection, err := executable.Inspect(appPath, classPath, signatures, false, cache)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inspection.Packages).To(BeNil())
+		Expect(inspection.DangerousClasses).To(HaveLen(1))
+		Expect(cache.Hits + cache.Misses).To(BeZero())
+	})
+
+	it("does not cache the libraries of archives with problems", func() {
+		cache := executable.NewPackageCache(t.TempDir())
+
+		_, err := executable.Inspect(appPath, classPath, signatures, true, cache)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cache.Misses).To(Equal(3))
+
+		cache = executable.NewPackageCache(cache.Path)
+		_, err = executable.Inspect(appPath, classPath, signatures, true, cache)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cache.Hits).To(Equal(2))
+		Expect(cache.Misses).To(Equal(1))
+	})
+
+	it("finds the libraries of archives again once dangerous classes are removed", func() {
+		inspection, err := executable.Inspect(appPath, classPath, signatures, true, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		d := inspection.DangerousClasses[0]
+		Expect(executable.RemoveDangerousClass(appPath, d)).To(Succeed())
+
+		packages, err := executable.Reinventory(appPath, inspection.Packages, []string{d.Location}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := os.ReadFile(filepath.Join(appPath, "lib", "log4j-core-2.14.1.jar"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(packages).To(HaveLen(4))
+		Expect(packages).To(ContainElement(And(
+			HaveField("Location", "lib/log4j-core-2.14.1.jar"),
+			HaveField("SHA256", fmt.Sprintf("%x", sha256.Sum256(b))),
+		)))
+		for _, p := range inspection.Packages {
+			if p.Location != "lib/log4j-core-2.14.1.jar" {
+				Expect(packages).To(ContainElement(p))
+			}
+		}
+	})
+}
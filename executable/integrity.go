@@ -0,0 +1,142 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IntegrityProblem is a problem with the structure or an entry of an archive.
+type IntegrityProblem struct {
+
+	// Location is the location of the archive, see Package.
+	Location string
+
+	// Entry is the name of the offending entry, empty if the problem is with the archive itself.
+	Entry string
+
+	Problem string
+}
+
+func (i IntegrityProblem) String() string {
+	if i.Entry == "" {
+		return fmt.Sprintf("%s: %s", i.Location, i.Problem)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Location, i.Entry, i.Problem)
+}
+
+// checkArchiveIntegrity passes the problems of the archive z of size bytes at location to report, and the contents of
+// each archive nested within it that can be read to nested as soon as it has been read.
+func checkArchiveIntegrity(z *zip.Reader, size int64, location string, report func(IntegrityProblem), nested func(string, []byte) error) error {
+	problem := func(entry string, format string, a ...interface{}) {
+		report(IntegrityProblem{Location: location, Entry: entry, Problem: fmt.Sprintf(format, a...)})
+	}
+
+	type extent struct {
+		name       string
+		start, end int64
+	}
+	var extents []extent
+
+	names := map[string]bool{}
+	for _, f := range z.File {
+		if names[f.Name] {
+			problem(f.Name, "is a duplicate entry")
+		}
+		names[f.Name] = true
+
+		if isUnsafePath(f.Name) {
+			problem(f.Name, "would be extracted outside of the target directory")
+		}
+
+		offset, err := f.DataOffset()
+		if err != nil {
+			problem(f.Name, "has an invalid local header: %s", err)
+			continue
+		}
+		if end := offset + int64(f.CompressedSize64); end > size || end < offset {
+			problem(f.Name, "extends past the end of the archive, which may be truncated")
+			continue
+		} else if f.CompressedSize64 > 0 {
+			extents = append(extents, extent{name: f.Name, start: offset, end: end})
+		}
+
+		c, err := checkEntry(f)
+		if err != nil {
+			problem(f.Name, "cannot be read: %s", err)
+			continue
+		}
+
+		if isArchive(f.Name) && !f.FileInfo().IsDir() {
+			if err := nested(f.Name, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	sort.Slice(extents, func(i, j int) bool {
+		return extents[i].start < extents[j].start
+	})
+	for i, last := 1, 0; i < len(extents); i++ {
+		if extents[i].start < extents[last].end {
+			problem(extents[i].name, "overlaps the data of %s", extents[last].name)
+		}
+		if extents[i].end > extents[last].end {
+			last = i
+		}
+	}
+
+	return nil
+}
+
+// checkEntry reads the whole entry f, which verifies its size and CRC, and returns its contents if it is a nested
+// archive.
+func checkEntry(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	if isArchive(f.Name) && !f.FileInfo().IsDir() {
+		return io.ReadAll(r)
+	}
+
+	_, err = io.Copy(io.Discard, r)
+	return nil, err
+}
+
+// isUnsafePath reports whether the entry name is absolute, has a volume name or backslashes, or has .. elements, any
+// of which could place it outside of the directory it is extracted to.
+func isUnsafePath(name string) bool {
+	if strings.HasPrefix(name, "/") || strings.Contains(name, `\`) || filepath.VolumeName(name) != "" ||
+		(len(name) > 1 && name[1] == ':') {
+		return true
+	}
+
+	for _, e := range strings.Split(name, "/") {
+		if e == ".." {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+// createStoredZIP writes the uncompressed entries, in order and including duplicates, to file and returns its contents
+func createStoredZIP(t *testing.T, file string, entries [][2]string) []byte {
+	t.Helper()
+
+	out := &bytes.Buffer{}
+	z := zip.NewWriter(out)
+	for _, e := range entries {
+		w, err := z.CreateHeader(&zip.FileHeader{Name: e[0], Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(e[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(file, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func testIntegrity(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir string
+		jar string
+	)
+
+	it.Before(func() {
+		dir = t.TempDir()
+		jar = filepath.Join(dir, "test.jar")
+	})
+
+	check := func(classPath []string) []executable.IntegrityProblem {
+		inspection, err := executable.Inspect("", classPath, nil, false, nil)
+		Expect(err).NotTo(HaveOccurred())
+		return inspection.Problems
+	}
+
+	it("finds no problems in valid archives", func() {
+		CreateZIP(t, jar, map[string]string{
+			"META-INF/MANIFEST.MF":   "Main-Class: com.example.Main\n",
+			"com/example/Main.class": "main",
+		})
+
+		problems := check([]string{jar, dir, filepath.Join(dir, "missing.jar")})
+		Expect(problems).To(BeEmpty())
+	})
+
+	it("finds entries whose CRC does not match", func() {
+		b := createStoredZIP(t, jar, [][2]string{
+			{"META-INF/MANIFEST.MF", "Main-Class: com.example.Main\n"},
+			{"com/example/Main.class", "original"},
+		})
+		Expect(os.WriteFile(jar, bytes.Replace(b, []byte("original"), []byte("modified"), 1), 0644)).To(Succeed())
+
+		problems := check([]string{jar})
+		Expect(problems).To(ConsistOf(executable.IntegrityProblem{
+			Location: filepath.ToSlash(jar),
+			Entry:    "com/example/Main.class",
+			Problem:  "cannot be read: zip: checksum error",
+		}))
+	})
+
+	it("finds truncated archives", func() {
+		b := createStoredZIP(t, jar, [][2]string{
+			{"META-INF/MANIFEST.MF", "Main-Class: com.example.Main\n"},
+			{"com/example/Main.class", "main"},
+		})
+		Expect(os.WriteFile(jar, b[:len(b)/2], 0644)).To(Succeed())
+
+		problems := check([]string{jar})
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Entry).To(BeEmpty())
+		Expect(problems[0].Problem).To(HavePrefix("is not a valid archive"))
+	})
+
+	it("finds duplicate entries", func() {
+		createStoredZIP(t, jar, [][2]string{
+			{"com/example/Main.class", "first"},
+			{"com/example/Main.class", "second"},
+		})
+
+		problems := check([]string{jar})
+		Expect(problems).To(ConsistOf(executable.IntegrityProblem{
+			Location: filepath.ToSlash(jar),
+			Entry:    "com/example/Main.class",
+			Problem:  "is a duplicate entry",
+		}))
+	})
+
+	it("finds entries that would be extracted outside of the target directory", func() {
+		createStoredZIP(t, jar, [][2]string{
+			{"../../etc/profile.d/evil.sh", "evil"},
+			{"/etc/passwd", "evil"},
+			{`..\evil.bat`, "evil"},
+			{"com/example/..class", "fine"},
+		})
+
+		problems := check([]string{jar})
+		Expect(problems).To(HaveLen(3))
+		Expect(problems).To(HaveEach(HaveField("Problem", "would be extracted outside of the target directory")))
+		Expect(problems).To(ConsistOf(
+			HaveField("Entry", "../../etc/profile.d/evil.sh"),
+			HaveField("Entry", "/etc/passwd"),
+			HaveField("Entry", `..\evil.bat`),
+		))
+	})
+
+	it("checks the archives nested in archives", func() {
+		nested := createStoredZIP(t, filepath.Join(dir, "nested.jar"), [][2]string{
+			{"com/example/Library.class", "original"},
+		})
+		nested = bytes.Replace(nested, []byte("original"), []byte("modified"), 1)
+		createStoredZIP(t, jar, [][2]string{
+			{"BOOT-INF/lib/nested.jar", string(nested)},
+		})
+
+		problems := check([]string{jar})
+		Expect(problems).To(ConsistOf(executable.IntegrityProblem{
+			Location: filepath.ToSlash(jar) + ":BOOT-INF/lib/nested.jar",
+			Entry:    "com/example/Library.class",
+			Problem:  "cannot be read: zip: checksum error",
+		}))
+	})
+
+	it("checks the archives in directories on the classpath", func() {
+		Expect(os.MkdirAll(filepath.Join(dir, "BOOT-INF", "lib"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "BOOT-INF", "classes"), 0755)).To(Succeed())
+		library := filepath.Join(dir, "BOOT-INF", "lib", "library.jar")
+		b := createStoredZIP(t, library, [][2]string{
+			{"com/example/Library.class", "original"},
+		})
+		Expect(os.WriteFile(library, bytes.Replace(b, []byte("original"), []byte("modified"), 1), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "BOOT-INF", "classes", "application.properties"), []byte{}, 0644)).To(Succeed())
+
+		problems := check([]string{filepath.Join(dir, "BOOT-INF", "classes"), dir, library})
+		Expect(problems).To(ConsistOf(executable.IntegrityProblem{
+			Location: filepath.ToSlash(library),
+			Entry:    "com/example/Library.class",
+			Problem:  "cannot be read: zip: checksum error",
+		}))
+	})
+
+	it("describes problems with the offending entry", func() {
+		Expect(executable.IntegrityProblem{Location: "app.jar", Entry: "a.class", Problem: "is a duplicate entry"}.String()).
+			To(Equal("app.jar: a.class: is a duplicate entry"))
+		Expect(executable.IntegrityProblem{Location: "app.jar", Problem: "is not a valid archive"}.String()).
+			To(Equal("app.jar: is not a valid archive"))
+	})
+}
@@ -0,0 +1,539 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	// registers the digests of signed JARs with crypto.Hash
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/smallstep/pkcs7"
+)
+
+// SignatureTrustStoreBindingType is the type of the binding holding the PEM encoded certificates that signers of JARs
+// must chain to.
+const SignatureTrustStoreBindingType = "signature-trust-store"
+
+// The values of $BP_EXECUTABLE_JAR_VERIFY_SIGNATURES, what to do with JARs whose signatures are not valid.
+const (
+	VerifySignaturesEnforce = "enforce"
+	VerifySignaturesWarn    = "warn"
+)
+
+// signatureDigests are the digest algorithms of signed JARs, by the prefix of their attribute names.
+var signatureDigests = map[string]crypto.Hash{
+	"sha-1":   crypto.SHA1,
+	"sha-256": crypto.SHA256,
+	"sha-384": crypto.SHA384,
+	"sha-512": crypto.SHA512,
+}
+
+// JARSignature is the result of verifying the signatures of a signed JAR.
+type JARSignature struct {
+
+	// Location is the location of the JAR, see Package.
+	Location string
+
+	// Signers are the subjects of the certificates that signed the JAR.
+	Signers []string
+
+	// Problems are the reasons the signatures of the JAR are not valid, empty if they are.
+	Problems []string
+}
+
+// Valid reports whether the signatures of the JAR are valid.
+func (j JARSignature) Valid() bool {
+	return len(j.Problems) == 0
+}
+
+func (j JARSignature) String() string {
+	if j.Valid() {
+		return fmt.Sprintf("%s signed by %s", j.Location, strings.Join(j.Signers, ", "))
+	}
+	return fmt.Sprintf("%s: %s", j.Location, strings.Join(j.Problems, ", "))
+}
+
+// LoadTrustStore returns a pool of the certificates in the files of binding.
+func LoadTrustStore(binding libcnb.Binding) (*x509.CertPool, error) {
+	var keys []string
+	for k := range binding.Secret {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pool := x509.NewCertPool()
+	for _, k := range keys {
+		if !pool.AppendCertsFromPEM([]byte(binding.Secret[k])) {
+			return nil, fmt.Errorf("unable to find PEM encoded certificates in %s of binding %s", k, binding.Name)
+		}
+	}
+
+	return pool, nil
+}
+
+// VerifyJARSignatures verifies the signatures of the signed JARs on the runtime classPath of the application and of
+// the archives nested within them, such as the libraries of a Spring Boot JAR. The digests of the entries must match
+// the manifest, the digests of the manifest must match each signature file, and each signature file must be signed by
+// a certificate that chains to trustStore. Unsigned JARs are not returned.
+func VerifyJARSignatures(classPath []string, trustStore *x509.CertPool) ([]JARSignature, error) {
+	var (
+		dirs   []string
+		signed []JARSignature
+	)
+
+	for _, c := range classPath {
+		if within(dirs, c) {
+			continue
+		}
+
+		fi, err := os.Stat(c)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to stat %s\n%w", c, err)
+		}
+
+		var s []JARSignature
+		if fi.IsDir() {
+			dirs = append(dirs, c)
+			s, err = verifyDirectorySignatures(c, trustStore)
+		} else if isArchive(c) {
+			var b []byte
+			if b, err = os.ReadFile(c); err != nil {
+				return nil, fmt.Errorf("unable to read %s\n%w", c, err)
+			}
+			s, err = verifyArchiveSignatures(b, filepath.ToSlash(c), trustStore)
+		}
+		if err != nil {
+			return nil, err
+		}
+		signed = append(signed, s...)
+	}
+
+	return signed, nil
+}
+
+// within reports whether file is within any of dirs.
+func within(dirs []string, file string) bool {
+	for _, d := range dirs {
+		if rel, err := filepath.Rel(d, file); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyDirectorySignatures verifies the signatures of the exploded JAR dir and of the archives within it.
+func verifyDirectorySignatures(dir string, trustStore *x509.CertPool) ([]JARSignature, error) {
+	var signed []JARSignature
+
+	fsys := os.DirFS(dir)
+	if s, ok, err := verifyJAR(fsys, filepath.ToSlash(dir), trustStore); err != nil {
+		return nil, err
+	} else if ok {
+		signed = append(signed, s)
+	}
+
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !isArchive(name) {
+			return nil
+		}
+
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("unable to read %s\n%w", name, err)
+		}
+
+		s, err := verifyArchiveSignatures(b, path.Join(filepath.ToSlash(dir), name), trustStore)
+		if err != nil {
+			return err
+		}
+		signed = append(signed, s...)
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to verify signatures in %s\n%w", dir, err)
+	}
+
+	return signed, nil
+}
+
+// verifyArchiveSignatures verifies the signatures of the archive b at location, followed by those of the archives
+// nested within it.
+func verifyArchiveSignatures(b []byte, location string, trustStore *x509.CertPool) ([]JARSignature, error) {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s\n%w", location, err)
+	}
+
+	var signed []JARSignature
+	if s, ok, err := verifyJAR(z, location, trustStore); err != nil {
+		return nil, err
+	} else if ok {
+		signed = append(signed, s)
+	}
+
+	for _, f := range z.File {
+		if !isArchive(f.Name) || f.FileInfo().IsDir() {
+			continue
+		}
+
+		c, err := readEntry(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s in %s\n%w", f.Name, location, err)
+		}
+
+		s, err := verifyArchiveSignatures(c, location+":"+f.Name, trustStore)
+		if err != nil {
+			return nil, err
+		}
+		signed = append(signed, s...)
+	}
+
+	return signed, nil
+}
+
+// verifyJAR verifies the signatures of the JAR fsys at location in the way the JVM does, returning false if it is not
+// signed.
+func verifyJAR(fsys fs.FS, location string, trustStore *x509.CertPool) (JARSignature, bool, error) {
+	entries, err := fs.ReadDir(fsys, "META-INF")
+	if errors.Is(err, fs.ErrNotExist) {
+		return JARSignature{}, false, nil
+	} else if err != nil {
+		return JARSignature{}, false, fmt.Errorf("unable to list META-INF of %s\n%w", location, err)
+	}
+
+	var signatureFiles []string
+	blocks := map[string]string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		base, ext := splitExt(e.Name())
+		switch ext {
+		case ".sf":
+			signatureFiles = append(signatureFiles, e.Name())
+		case ".rsa", ".dsa", ".ec":
+			blocks[base] = e.Name()
+		}
+	}
+	if len(signatureFiles) == 0 {
+		return JARSignature{}, false, nil
+	}
+
+	signature := JARSignature{Location: location}
+
+	b, err := fs.ReadFile(fsys, "META-INF/MANIFEST.MF")
+	if errors.Is(err, fs.ErrNotExist) {
+		signature.Problems = append(signature.Problems, "META-INF/MANIFEST.MF is missing")
+		return signature, true, nil
+	} else if err != nil {
+		return JARSignature{}, false, fmt.Errorf("unable to read META-INF/MANIFEST.MF of %s\n%w", location, err)
+	}
+	manifest := parseManifestSections(b)
+
+	// entries covered by a valid signature file
+	signedEntries := map[string]bool{}
+
+	for _, sf := range signatureFiles {
+		base, _ := splitExt(sf)
+		block, ok := blocks[base]
+		if !ok {
+			signature.Problems = append(signature.Problems, fmt.Sprintf("%s has no signature block", sf))
+			continue
+		}
+
+		sfBytes, err := fs.ReadFile(fsys, path.Join("META-INF", sf))
+		if err != nil {
+			return JARSignature{}, false, fmt.Errorf("unable to read META-INF/%s of %s\n%w", sf, location, err)
+		}
+		blockBytes, err := fs.ReadFile(fsys, path.Join("META-INF", block))
+		if err != nil {
+			return JARSignature{}, false, fmt.Errorf("unable to read META-INF/%s of %s\n%w", block, location, err)
+		}
+
+		signer, err := verifySignatureBlock(blockBytes, sfBytes, trustStore)
+		if err != nil {
+			signature.Problems = append(signature.Problems, fmt.Sprintf("%s is not signed by a trusted signer: %s", block, err))
+			continue
+		}
+		signature.Signers = append(signature.Signers, signer)
+
+		covered, problems := verifySignatureFile(sf, parseManifestSections(sfBytes), b, manifest)
+		signature.Problems = append(signature.Problems, problems...)
+		for _, name := range covered {
+			signedEntries[name] = true
+		}
+	}
+
+	for _, s := range manifest[1:] {
+		if !signedEntries[s.Name] {
+			continue
+		}
+
+		if problem, ok := verifyEntryDigest(fsys, s); !ok {
+			signature.Problems = append(signature.Problems, problem)
+		}
+	}
+
+	// entries are only reported as unsigned if there is a signer they could have been signed by
+	if len(signature.Signers) == 0 {
+		return signature, true, nil
+	}
+
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && !isSignatureRelated(name) && !signedEntries[name] {
+			signature.Problems = append(signature.Problems, fmt.Sprintf("%s is not signed", name))
+		}
+		return nil
+	}); err != nil {
+		return JARSignature{}, false, fmt.Errorf("unable to list %s\n%w", location, err)
+	}
+
+	return signature, true, nil
+}
+
+// verifySignatureBlock verifies that the PKCS#7 signature block is a signature of signatureFile by a certificate that
+// chains to trustStore and returns the subject of the signer.
+func verifySignatureBlock(block []byte, signatureFile []byte, trustStore *x509.CertPool) (string, error) {
+	p7, err := pkcs7.Parse(block)
+	if err != nil {
+		return "", err
+	}
+
+	p7.Content = signatureFile
+	if err := p7.VerifyWithChain(trustStore); err != nil {
+		return "", err
+	}
+
+	signer := p7.GetOnlySigner()
+	if signer == nil {
+		return "", fmt.Errorf("signature block has more than one signer")
+	}
+	return signer.Subject.String(), nil
+}
+
+// verifySignatureFile checks the digests of the signature file sf against the manifest and returns the names of the
+// entries it covers. As in the JVM, the per-entry digests of the manifest sections are only checked if the digest of
+// the whole manifest does not match.
+func verifySignatureFile(sf string, sections []manifestSection, raw []byte, manifest []manifestSection) ([]string, []string) {
+	if len(sections) == 0 {
+		return nil, []string{fmt.Sprintf("%s is empty", sf)}
+	}
+
+	var covered, problems []string
+	for _, s := range sections[1:] {
+		covered = append(covered, s.Name)
+	}
+
+	if ok, found := matchDigests(sections[0], "-digest-manifest", raw); found && ok {
+		return covered, nil
+	}
+
+	if ok, found := matchDigests(sections[0], "-digest-manifest-main-attributes", manifest[0].Raw); found && !ok {
+		problems = append(problems, fmt.Sprintf("%s does not match the main attributes of META-INF/MANIFEST.MF", sf))
+	}
+
+	raws := map[string][]byte{}
+	for _, s := range manifest[1:] {
+		raws[s.Name] = s.Raw
+	}
+
+	covered = nil
+	for _, s := range sections[1:] {
+		r, ok := raws[s.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s signs %s, which is not in META-INF/MANIFEST.MF", sf, s.Name))
+			continue
+		}
+
+		if ok, found := matchDigests(s, "-digest", r); !found {
+			problems = append(problems, fmt.Sprintf("%s has no supported digest of %s", sf, s.Name))
+		} else if !ok {
+			problems = append(problems, fmt.Sprintf("%s does not match the manifest section of %s", sf, s.Name))
+		} else {
+			covered = append(covered, s.Name)
+		}
+	}
+
+	return covered, problems
+}
+
+// verifyEntryDigest checks the contents of the entry of the manifest section s against its digests.
+func verifyEntryDigest(fsys fs.FS, s manifestSection) (string, bool) {
+	b, err := fs.ReadFile(fsys, s.Name)
+	if err != nil {
+		return fmt.Sprintf("%s is missing", s.Name), false
+	}
+
+	if ok, found := matchDigests(s, "-digest", b); !found {
+		return fmt.Sprintf("%s has no supported digest", s.Name), false
+	} else if !ok {
+		return fmt.Sprintf("digest of %s does not match", s.Name), false
+	}
+	return "", true
+}
+
+// matchDigests reports whether content matches every supported digest of section whose attribute names end with
+// suffix, and whether there are any.
+func matchDigests(s manifestSection, suffix string, content []byte) (bool, bool) {
+	found := false
+
+	for name, value := range s.Attributes {
+		algorithm, ok := strings.CutSuffix(name, suffix)
+		if !ok {
+			continue
+		}
+		h, ok := signatureDigests[algorithm]
+		if !ok {
+			continue
+		}
+
+		found = true
+		d := h.New()
+		d.Write(content)
+		if base64.StdEncoding.EncodeToString(d.Sum(nil)) != value {
+			return false, true
+		}
+	}
+
+	return found, found
+}
+
+// isSignatureRelated reports whether name is the manifest or a signature file or block, which are not signed.
+func isSignatureRelated(name string) bool {
+	dir, file := path.Split(name)
+	if !strings.EqualFold(dir, "META-INF/") {
+		return false
+	}
+
+	if strings.EqualFold(file, "MANIFEST.MF") || strings.HasPrefix(strings.ToUpper(file), "SIG-") {
+		return true
+	}
+
+	_, ext := splitExt(file)
+	return ext == ".sf" || ext == ".rsa" || ext == ".dsa" || ext == ".ec"
+}
+
+// splitExt returns the upper case base name and the lower case extension of file.
+func splitExt(file string) (string, string) {
+	ext := path.Ext(file)
+	return strings.ToUpper(strings.TrimSuffix(file, ext)), strings.ToLower(ext)
+}
+
+// manifestSection is a section of a manifest or signature file.
+type manifestSection struct {
+
+	// Name is the value of the Name attribute, empty for the main section.
+	Name string
+
+	// Attributes are the attributes of the section, by their lower case names.
+	Attributes map[string]string
+
+	// Raw is the bytes of the section including the empty line that ends it, which are what is digested.
+	Raw []byte
+}
+
+// parseManifestSections splits a manifest or signature file into its sections, the first of which is the main
+// section.
+func parseManifestSections(b []byte) []manifestSection {
+	var (
+		sections []manifestSection
+		lines    []string
+		start    int
+	)
+
+	end := func(i int) {
+		s := manifestSection{Attributes: map[string]string{}, Raw: b[start:i]}
+		for _, l := range lines {
+			k, v, _ := strings.Cut(l, ":")
+			s.Attributes[strings.ToLower(strings.TrimSpace(k))] = strings.TrimPrefix(v, " ")
+		}
+		s.Name = s.Attributes["name"]
+
+		if len(sections) == 0 || s.Name != "" {
+			sections = append(sections, s)
+		}
+		lines, start = nil, i
+	}
+
+	for i := 0; i < len(b); {
+		j := i
+		for j < len(b) && b[j] != '\r' && b[j] != '\n' {
+			j++
+		}
+		line := string(b[i:j])
+
+		if j < len(b) && b[j] == '\r' {
+			j++
+		}
+		if j < len(b) && b[j] == '\n' {
+			j++
+		}
+
+		switch {
+		case line == "":
+			end(j)
+		case strings.HasPrefix(line, " ") && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+		i = j
+	}
+	if start < len(b) || len(sections) == 0 {
+		end(len(b))
+	}
+
+	return sections
+}
+
+// resolveVerifySignatures returns the value of $BP_EXECUTABLE_JAR_VERIFY_SIGNATURES, empty if signatures are not
+// verified.
+func resolveVerifySignatures(cr libpak.ConfigurationResolver) (string, error) {
+	s, _ := cr.Resolve("BP_EXECUTABLE_JAR_VERIFY_SIGNATURES")
+	switch s {
+	case "", VerifySignaturesEnforce, VerifySignaturesWarn:
+		return s, nil
+	default:
+		return "", fmt.Errorf("$BP_EXECUTABLE_JAR_VERIFY_SIGNATURES must be warn or enforce, not %q", s)
+	}
+}
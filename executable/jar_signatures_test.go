@@ -0,0 +1,300 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/smallstep/pkcs7"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+// jarSigner is a certificate and key that sign JARs
+type jarSigner struct {
+	Certificate *x509.Certificate
+	Key         *ecdsa.PrivateKey
+}
+
+// PEM returns the certificate of the signer PEM encoded
+func (s jarSigner) PEM() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate.Raw}))
+}
+
+// newJARSigner returns a self-signed signer named cn
+func newJARSigner(t *testing.T, cn string) jarSigner {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return jarSigner{Certificate: cert, Key: key}
+}
+
+// signJAR returns entries with the manifest, signature file and signature block of a JAR signed by signer, digesting
+// the whole manifest if wholeManifest is true and only its sections otherwise
+func signJAR(t *testing.T, entries map[string]string, signer jarSigner, wholeManifest bool) map[string]string {
+	t.Helper()
+
+	digest := func(s string) string {
+		d := sha256.Sum256([]byte(s))
+		return base64.StdEncoding.EncodeToString(d[:])
+	}
+
+	var names []string
+	for n := range entries {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	manifest := &strings.Builder{}
+	manifest.WriteString("Manifest-Version: 1.0\r\nCreated-By: test\r\n\r\n")
+	sf := &strings.Builder{}
+	sf.WriteString("Signature-Version: 1.0\r\n")
+
+	var sections []string
+	for _, n := range names {
+		section := fmt.Sprintf("Name: %s\r\nSHA-256-Digest: %s\r\n\r\n", n, digest(entries[n]))
+		manifest.WriteString(section)
+		sections = append(sections, fmt.Sprintf("Name: %s\r\nSHA-256-Digest: %s\r\n\r\n", n, digest(section)))
+	}
+
+	if wholeManifest {
+		sf.WriteString(fmt.Sprintf("SHA-256-Digest-Manifest: %s\r\n", digest(manifest.String())))
+	}
+	sf.WriteString("\r\n")
+	for _, s := range sections {
+		sf.WriteString(s)
+	}
+
+	sd, err := pkcs7.NewSignedData([]byte(sf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	if err := sd.AddSigner(signer.Certificate, signer.Key, pkcs7.SignerInfoConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	sd.Detach()
+	block, err := sd.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed := map[string]string{
+		"META-INF/MANIFEST.MF": manifest.String(),
+		"META-INF/SIGNER.SF":   sf.String(),
+		"META-INF/SIGNER.EC":   string(block),
+	}
+	for n, c := range entries {
+		signed[n] = c
+	}
+	return signed
+}
+
+func testJARSignatures(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir        string
+		signer     jarSigner
+		trustStore *x509.CertPool
+	)
+
+	it.Before(func() {
+		dir = t.TempDir()
+		signer = newJARSigner(t, "Example Signer")
+
+		trustStore = x509.NewCertPool()
+		trustStore.AddCert(signer.Certificate)
+	})
+
+	classes := map[string]string{
+		"com/example/Main.class":   "main",
+		"com/example/Helper.class": "helper",
+	}
+
+	it("verifies a signed JAR", func() {
+		CreateZIP(t, filepath.Join(dir, "signed.jar"), signJAR(t, classes, signer, true))
+
+		signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "signed.jar")}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed).To(Equal([]executable.JARSignature{
+			{Location: filepath.ToSlash(filepath.Join(dir, "signed.jar")), Signers: []string{"CN=Example Signer"}},
+		}))
+		Expect(signed[0].Valid()).To(BeTrue())
+	})
+
+	it("verifies the manifest sections if the whole manifest is not digested", func() {
+		CreateZIP(t, filepath.Join(dir, "signed.jar"), signJAR(t, classes, signer, false))
+
+		signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "signed.jar")}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed).To(HaveLen(1))
+		Expect(signed[0].Problems).To(BeEmpty())
+	})
+
+	it("does not return unsigned JARs", func() {
+		CreateZIP(t, filepath.Join(dir, "unsigned.jar"), classes)
+
+		signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "unsigned.jar")}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed).To(BeEmpty())
+	})
+
+	it("skips classpath entries that do not exist", func() {
+		signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "missing.jar")}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed).To(BeEmpty())
+	})
+
+	it("finds entries that were changed", func() {
+		entries := signJAR(t, classes, signer, true)
+		entries["com/example/Main.class"] = "tampered"
+		CreateZIP(t, filepath.Join(dir, "signed.jar"), entries)
+
+		signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "signed.jar")}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed[0].Valid()).To(BeFalse())
+		Expect(signed[0].Problems).To(ConsistOf("digest of com/example/Main.class does not match"))
+	})
+
+	it("finds entries that were added", func() {
+		entries := signJAR(t, classes, signer, true)
+		entries["com/example/Added.class"] = "added"
+		CreateZIP(t, filepath.Join(dir, "signed.jar"), entries)
+
+		signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "signed.jar")}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed[0].Problems).To(ConsistOf("com/example/Added.class is not signed"))
+	})
+
+	it("finds manifests that were changed", func() {
+		entries := signJAR(t, classes, signer, false)
+		entries["META-INF/MANIFEST.MF"] = strings.Replace(entries["META-INF/MANIFEST.MF"], "Name: com/example/Main.class\r\n", "Name: com/example/Main.class\r\nX-Changed: true\r\n", 1)
+		CreateZIP(t, filepath.Join(dir, "signed.jar"), entries)
+
+		signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "signed.jar")}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed[0].Problems).To(ConsistOf(
+			"SIGNER.SF does not match the manifest section of com/example/Main.class",
+			"com/example/Main.class is not signed",
+		))
+	})
+
+	it("finds signature files that were changed", func() {
+		entries := signJAR(t, classes, signer, true)
+		entries["META-INF/SIGNER.SF"] = strings.Replace(entries["META-INF/SIGNER.SF"], "Signature-Version: 1.0", "Signature-Version: 2.0", 1)
+		CreateZIP(t, filepath.Join(dir, "signed.jar"), entries)
+
+		signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "signed.jar")}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed[0].Problems).To(ConsistOf(HavePrefix("SIGNER.EC is not signed by a trusted signer")))
+	})
+
+	it("does not trust signers that do not chain to the trust store", func() {
+		CreateZIP(t, filepath.Join(dir, "signed.jar"), signJAR(t, classes, newJARSigner(t, "Other Signer"), true))
+
+		signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "signed.jar")}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed[0].Signers).To(BeEmpty())
+		Expect(signed[0].Problems).To(ConsistOf(ContainSubstring("certificate signed by unknown authority")))
+	})
+
+	it("verifies signed JARs nested in archives and exploded JARs", func() {
+		CreateZIP(t, filepath.Join(dir, "nested.jar"), signJAR(t, classes, signer, true))
+		b, err := os.ReadFile(filepath.Join(dir, "nested.jar"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(dir, "app", "BOOT-INF", "lib"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "app", "BOOT-INF", "lib", "nested.jar"), b, 0644)).To(Succeed())
+		CreateZIP(t, filepath.Join(dir, "outer.jar"), map[string]string{"BOOT-INF/lib/nested.jar": string(b)})
+
+		signed, err := executable.VerifyJARSignatures([]string{
+			filepath.Join(dir, "app"),
+			filepath.Join(dir, "app", "BOOT-INF", "lib", "nested.jar"),
+			filepath.Join(dir, "outer.jar"),
+		}, trustStore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signed).To(HaveLen(2))
+		Expect(signed[0].Location).To(Equal(filepath.ToSlash(filepath.Join(dir, "app", "BOOT-INF", "lib", "nested.jar"))))
+		Expect(signed[1].Location).To(Equal(filepath.ToSlash(filepath.Join(dir, "outer.jar")) + ":BOOT-INF/lib/nested.jar"))
+		Expect(signed[1].Valid()).To(BeTrue())
+	})
+
+	context("LoadTrustStore", func() {
+		it("loads the certificates of the binding", func() {
+			trustStore, err := executable.LoadTrustStore(libcnb.NewBinding("trust", dir, map[string]string{
+				"type":     executable.SignatureTrustStoreBindingType,
+				"ca.pem":   signer.PEM(),
+				"more.pem": newJARSigner(t, "Other Signer").PEM(),
+			}))
+			Expect(err).NotTo(HaveOccurred())
+
+			CreateZIP(t, filepath.Join(dir, "signed.jar"), signJAR(t, classes, signer, true))
+			signed, err := executable.VerifyJARSignatures([]string{filepath.Join(dir, "signed.jar")}, trustStore)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(signed[0].Valid()).To(BeTrue())
+		})
+
+		it("returns an error for files without certificates", func() {
+			_, err := executable.LoadTrustStore(libcnb.NewBinding("trust", dir, map[string]string{
+				"ca.pem": "not a certificate",
+			}))
+			Expect(err).To(MatchError("unable to find PEM encoded certificates in ca.pem of binding trust"))
+		})
+	})
+}
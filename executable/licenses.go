@@ -0,0 +1,351 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// LicenseNames maps the names and URLs libraries commonly use for their licenses, lower case, without punctuation and
+// with single spaces, to SPDX license identifiers.
+var LicenseNames = map[string]string{
+	"apache 20":                                   "Apache-2.0",
+	"apache license 20":                           "Apache-2.0",
+	"apache license version 20":                   "Apache-2.0",
+	"apache software license version 20":          "Apache-2.0",
+	"the apache license version 20":               "Apache-2.0",
+	"the apache software license version 20":      "Apache-2.0",
+	"httpswwwapacheorglicenseslicense20":          "Apache-2.0",
+	"httpswwwapacheorglicenseslicense20txt":       "Apache-2.0",
+	"httpwwwapacheorglicenseslicense20":           "Apache-2.0",
+	"httpwwwapacheorglicenseslicense20txt":        "Apache-2.0",
+	"mit":                                         "MIT",
+	"mit license":                                 "MIT",
+	"the mit license":                             "MIT",
+	"httpsopensourceorglicensesmit":               "MIT",
+	"httpopensourceorglicensesmit":                "MIT",
+	"bsd 2clause":                                 "BSD-2-Clause",
+	"bsd 2clause license":                         "BSD-2-Clause",
+	"bsd 3clause":                                 "BSD-3-Clause",
+	"bsd 3clause license":                         "BSD-3-Clause",
+	"new bsd license":                             "BSD-3-Clause",
+	"eclipse distribution license v 10":           "BSD-3-Clause",
+	"eclipse public license 10":                   "EPL-1.0",
+	"eclipse public license v 10":                 "EPL-1.0",
+	"eclipse public license v10":                  "EPL-1.0",
+	"eclipse public license 20":                   "EPL-2.0",
+	"eclipse public license v 20":                 "EPL-2.0",
+	"eclipse public license v20":                  "EPL-2.0",
+	"mozilla public license 20":                   "MPL-2.0",
+	"mozilla public license version 20":           "MPL-2.0",
+	"gnu lesser general public license":           "LGPL-2.1-only",
+	"gnu lesser general public license v21":       "LGPL-2.1-only",
+	"gnu lesser general public license v3":        "LGPL-3.0-only",
+	"gnu general public license v2":               "GPL-2.0-only",
+	"gnu general public license version 2":        "GPL-2.0-only",
+	"gnu general public license v3":               "GPL-3.0-only",
+	"gnu general public license version 3":        "GPL-3.0-only",
+	"gnu affero general public license v3":        "AGPL-3.0-only",
+	"gnu affero general public license version 3": "AGPL-3.0-only",
+	"gnu general public license version 2 with the classpath exception": "GPL-2.0-with-classpath-exception",
+	"cddl 10": "CDDL-1.0",
+	"cddl 11": "CDDL-1.1",
+}
+
+// LicenseTexts maps a phrase found in the text of a license, lower case with collapsed whitespace, to its SPDX
+// license identifier. They are used to identify the license files in META-INF.
+var LicenseTexts = map[string]string{
+	"apache license version 2.0":                                     "Apache-2.0",
+	"permission is hereby granted, free of charge":                   "MIT",
+	"eclipse public license - v 2.0":                                 "EPL-2.0",
+	"eclipse public license - v 1.0":                                 "EPL-1.0",
+	"mozilla public license version 2.0":                             "MPL-2.0",
+	"gnu lesser general public license version 2.1":                  "LGPL-2.1-only",
+	"gnu lesser general public license version 3":                    "LGPL-3.0-only",
+	"gnu affero general public license version 3":                    "AGPL-3.0-only",
+	"gnu general public license version 3":                           "GPL-3.0-only",
+	"common development and distribution license (cddl) version 1.1": "CDDL-1.1",
+}
+
+// NormalizeLicense returns the SPDX license identifier of a license name or URL, or the name itself if it is not
+// known.
+func NormalizeLicense(name string) string {
+	name = strings.TrimSpace(name)
+
+	key := strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == ' ':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return -1
+		}
+	}, name)), " ")
+
+	if id, ok := LicenseNames[key]; ok {
+		return id
+	}
+	return name
+}
+
+// isSPDXLicense reports whether license is one of the SPDX license identifiers of LicenseNames and LicenseTexts.
+func isSPDXLicense(license string) bool {
+	for _, m := range []map[string]string{LicenseNames, LicenseTexts} {
+		for _, id := range m {
+			if id == license {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spdxLicenseExpression returns the SPDX license expression offering a choice of licenses, or false if there are none
+// or one of them is not an SPDX license identifier.
+func spdxLicenseExpression(licenses []string) (string, bool) {
+	if len(licenses) == 0 {
+		return "", false
+	}
+	for _, l := range licenses {
+		if !isSPDXLicense(l) {
+			return "", false
+		}
+	}
+	return strings.Join(licenses, " OR "), true
+}
+
+// licenseFromText returns the SPDX license identifier of a license text, or false if it is not known.
+func licenseFromText(text []byte) (string, bool) {
+	s := strings.ToLower(strings.Join(strings.Fields(string(text)), " "))
+
+	// check the longest phrases first, as the LGPL contains the phrase of the GPL
+	var phrases []string
+	for p := range LicenseTexts {
+		phrases = append(phrases, p)
+	}
+	sort.Slice(phrases, func(i, j int) bool {
+		if len(phrases[i]) != len(phrases[j]) {
+			return len(phrases[i]) > len(phrases[j])
+		}
+		return phrases[i] < phrases[j]
+	})
+
+	for _, p := range phrases {
+		if strings.Contains(s, p) {
+			return LicenseTexts[p], true
+		}
+	}
+	return "", false
+}
+
+// licensesFromPOM returns the licenses declared in a pom.xml.
+func licensesFromPOM(b []byte) []string {
+	var pom struct {
+		Licenses []struct {
+			Name string `xml:"name"`
+			URL  string `xml:"url"`
+		} `xml:"licenses>license"`
+	}
+	if err := xml.NewDecoder(bytes.NewReader(b)).Decode(&pom); err != nil {
+		return nil
+	}
+
+	var licenses []string
+	for _, l := range pom.Licenses {
+		if l.Name != "" {
+			licenses = append(licenses, NormalizeLicense(l.Name))
+		} else if l.URL != "" {
+			licenses = append(licenses, NormalizeLicense(l.URL))
+		}
+	}
+	return licenses
+}
+
+// licensesFromBundle returns the licenses in an OSGi Bundle-License manifest header, a comma separated list of SPDX
+// license expressions or URLs that may be followed by attributes.
+func licensesFromBundle(header string) []string {
+	var licenses []string
+	for _, l := range strings.Split(header, ",") {
+		l, _, _ = strings.Cut(l, ";")
+		if l = strings.TrimSpace(l); l != "" && l != "<<EXTERNAL>>" {
+			licenses = append(licenses, NormalizeLicense(l))
+		}
+	}
+	return licenses
+}
+
+// isLicenseFile reports whether name is a license file in META-INF, such as META-INF/LICENSE.txt.
+func isLicenseFile(name string) bool {
+	return path.Dir(name) == "META-INF" && strings.HasPrefix(strings.ToUpper(path.Base(name)), "LICENSE")
+}
+
+// appendLicenses appends the licenses that are not in licenses already.
+func appendLicenses(licenses []string, more ...string) []string {
+	for _, m := range more {
+		found := false
+		for _, l := range licenses {
+			found = found || l == m
+		}
+		if !found {
+			licenses = append(licenses, m)
+		}
+	}
+	return licenses
+}
+
+// LicensePolicy is the licenses libraries may and may not use, glob patterns matched against their SPDX license
+// identifiers or, if they are not known, their names.
+type LicensePolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// NewLicensePolicy creates a LicensePolicy from $BP_EXECUTABLE_JAR_LICENSE_ALLOW and $BP_EXECUTABLE_JAR_LICENSE_DENY.
+func NewLicensePolicy(cr libpak.ConfigurationResolver) (LicensePolicy, error) {
+	var p LicensePolicy
+
+	for name, patterns := range map[string]*[]string{
+		"BP_EXECUTABLE_JAR_LICENSE_ALLOW": &p.Allow,
+		"BP_EXECUTABLE_JAR_LICENSE_DENY":  &p.Deny,
+	} {
+		s, _ := cr.Resolve(name)
+		for _, pattern := range strings.Split(s, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern == "" {
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return LicensePolicy{}, fmt.Errorf("invalid pattern %q in $%s\n%w", pattern, name, err)
+			}
+			*patterns = append(*patterns, pattern)
+		}
+	}
+
+	return p, nil
+}
+
+// Check returns a description of each package that violates the policy. A package may be used if one of its licenses
+// is allowed and not denied, as libraries that list several licenses usually offer a choice between them. If there
+// is an allow list, packages without a known license are violations.
+func (p LicensePolicy) Check(packages []Package) []string {
+	if len(p.Allow) == 0 && len(p.Deny) == 0 {
+		return nil
+	}
+
+	var violations []string
+	for _, pkg := range packages {
+		if len(pkg.Licenses) == 0 {
+			if len(p.Allow) > 0 {
+				violations = append(violations, fmt.Sprintf("%s at %s has no known license", pkg.PURL(), pkg.Location))
+			}
+			continue
+		}
+
+		acceptable := false
+		for _, l := range pkg.Licenses {
+			allowed := len(p.Allow) == 0 || matchLicense(p.Allow, l)
+			acceptable = acceptable || (allowed && !matchLicense(p.Deny, l))
+		}
+		if !acceptable {
+			violations = append(violations, fmt.Sprintf("%s at %s is licensed under %s", pkg.PURL(), pkg.Location, strings.Join(pkg.Licenses, ", ")))
+		}
+	}
+
+	return violations
+}
+
+func matchLicense(patterns []string, license string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), strings.ToLower(license)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LicenseReport contributes a launch layer holding licenses.json, the licenses of each library in the application.
+type LicenseReport struct {
+	Packages         []Package
+	LayerContributor libpak.LayerContributor
+	Logger           bard.Logger
+}
+
+// licenseReportEntry is the JSON form of a package in the license report.
+type licenseReportEntry struct {
+	PURL     string   `json:"purl"`
+	Location string   `json:"location"`
+	SHA256   string   `json:"sha256,omitempty"`
+	Licenses []string `json:"licenses"`
+}
+
+func NewLicenseReport(packages []Package) LicenseReport {
+	return LicenseReport{Packages: packages}
+}
+
+// Report returns the contents of licenses.json.
+func (l LicenseReport) Report() ([]byte, error) {
+	entries := []licenseReportEntry{}
+	for _, p := range l.Packages {
+		licenses := p.Licenses
+		if licenses == nil {
+			licenses = []string{}
+		}
+		entries = append(entries, licenseReportEntry{PURL: p.PURL(), Location: p.Location, SHA256: p.SHA256, Licenses: licenses})
+	}
+
+	return json.MarshalIndent(entries, "", "  ")
+}
+
+func (l LicenseReport) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	report, err := l.Report()
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create license report\n%w", err)
+	}
+
+	contributor := libpak.NewLayerContributor(
+		"License Report",
+		map[string]interface{}{"sha256": fmt.Sprintf("%x", sha256.Sum256(report))},
+		libcnb.LayerTypes{Launch: true},
+	)
+	contributor.Logger = l.Logger
+
+	return contributor.Contribute(layer, func() (libcnb.Layer, error) {
+		file := filepath.Join(layer.Path, "licenses.json")
+		if err := os.WriteFile(file, report, 0644); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to write %s\n%w", file, err)
+		}
+
+		return layer, nil
+	})
+}
+
+func (LicenseReport) Name() string {
+	return "licenses"
+}
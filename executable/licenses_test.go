@@ -0,0 +1,164 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testLicenses(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath string
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+	})
+
+	it("normalizes license names", func() {
+		Expect(executable.NormalizeLicense("The Apache Software License, Version 2.0")).To(Equal("Apache-2.0"))
+		Expect(executable.NormalizeLicense("https://www.apache.org/licenses/LICENSE-2.0.txt")).To(Equal("Apache-2.0"))
+		Expect(executable.NormalizeLicense("Eclipse Public License - v 2.0")).To(Equal("EPL-2.0"))
+		Expect(executable.NormalizeLicense(" MIT License ")).To(Equal("MIT"))
+		Expect(executable.NormalizeLicense("Example License")).To(Equal("Example License"))
+	})
+
+	context("Inventory", func() {
+		find := func() []executable.Package {
+			packages, err := executable.Inventory(appPath, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			return packages
+		}
+
+		it("reads the licenses of an embedded pom.xml", func() {
+			CreateZIP(t, filepath.Join(appPath, "lib.jar"), map[string]string{
+				"META-INF/maven/org.example/lib/pom.properties": "groupId=org.example\nartifactId=lib\nversion=1.0\n",
+				"META-INF/maven/org.example/lib/pom.xml": `<project><licenses>
+  <license><name>Apache License, Version 2.0</name></license>
+  <license><url>https://opensource.org/licenses/MIT</url></license>
+</licenses></project>`,
+				"META-INF/LICENSE": "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007",
+			})
+
+			Expect(find()).To(ConsistOf(HaveField("Licenses", []string{"Apache-2.0", "MIT"})))
+		})
+
+		it("reads the licenses of the Bundle-License and license files", func() {
+			CreateZIP(t, filepath.Join(appPath, "lib.jar"), map[string]string{
+				"META-INF/maven/org.example/lib/pom.properties": "groupId=org.example\nartifactId=lib\nversion=1.0\n",
+				"META-INF/MANIFEST.MF":                          "Bundle-License: EPL-2.0;link=\"https://www.eclipse.org/legal/epl-2.0\", Example License\n",
+				"META-INF/LICENSE.txt":                          "GNU LESSER GENERAL PUBLIC LICENSE\n  Version 2.1, February 1999",
+			})
+
+			Expect(find()).To(ConsistOf(HaveField("Licenses", []string{"EPL-2.0", "Example License", "LGPL-2.1-only"})))
+		})
+
+		it("reads the licenses of an exploded JAR", func() {
+			Expect(os.MkdirAll(filepath.Join(appPath, "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "META-INF", "MANIFEST.MF"),
+				[]byte("Implementation-Title: app\nImplementation-Version: 1.0.0\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "META-INF", "LICENSE"),
+				[]byte("Permission is hereby granted, free of charge, to any person"), 0644)).To(Succeed())
+
+			Expect(find()).To(ConsistOf(HaveField("Licenses", []string{"MIT"})))
+		})
+
+		it("finds no licenses", func() {
+			CreateZIP(t, filepath.Join(appPath, "lib-1.0.jar"), map[string]string{"META-INF/LICENSE": "All rights reserved."})
+
+			Expect(find()).To(ConsistOf(HaveField("Licenses", BeEmpty())))
+		})
+	})
+
+	context("LicensePolicy", func() {
+		packages := []executable.Package{
+			{Name: "apache", Location: "a.jar", Licenses: []string{"Apache-2.0"}},
+			{Name: "dual", Location: "b.jar", Licenses: []string{"GPL-2.0-only", "MIT"}},
+			{Name: "gpl", Location: "c.jar", Licenses: []string{"GPL-3.0-only"}},
+			{Name: "unknown", Location: "d.jar"},
+		}
+
+		it("allows everything by default", func() {
+			Expect(executable.LicensePolicy{}.Check(packages)).To(BeEmpty())
+		})
+
+		it("denies licenses", func() {
+			Expect(executable.LicensePolicy{Deny: []string{"gpl-*"}}.Check(packages)).To(Equal([]string{
+				"pkg:maven/gpl at c.jar is licensed under GPL-3.0-only",
+			}))
+		})
+
+		it("allows licenses", func() {
+			Expect(executable.LicensePolicy{Allow: []string{"Apache-2.0", "GPL-*"}, Deny: []string{"GPL-3.0*"}}.Check(packages)).To(Equal([]string{
+				"pkg:maven/gpl at c.jar is licensed under GPL-3.0-only",
+				"pkg:maven/unknown at d.jar has no known license",
+			}))
+		})
+
+		it("resolves the policy", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_LICENSE_ALLOW", "Apache-2.0, MIT")
+			t.Setenv("BP_EXECUTABLE_JAR_LICENSE_DENY", "AGPL-*")
+
+			Expect(executable.NewLicensePolicy(libpak.ConfigurationResolver{})).To(Equal(executable.LicensePolicy{
+				Allow: []string{"Apache-2.0", "MIT"},
+				Deny:  []string{"AGPL-*"},
+			}))
+		})
+
+		it("returns an error for an invalid pattern", func() {
+			t.Setenv("BP_EXECUTABLE_JAR_LICENSE_DENY", "GPL-[")
+
+			_, err := executable.NewLicensePolicy(libpak.ConfigurationResolver{})
+			Expect(err).To(MatchError(ContainSubstring(`invalid pattern "GPL-[" in $BP_EXECUTABLE_JAR_LICENSE_DENY`)))
+		})
+	})
+
+	it("contributes the license report", func() {
+		layers := libcnb.Layers{Path: t.TempDir()}
+		layer, err := layers.Layer("licenses")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = executable.NewLicenseReport([]executable.Package{
+			{Group: "org.example", Name: "lib", Version: "1.0", Location: "lib.jar", SHA256: "abc", Licenses: []string{"MIT"}},
+			{Name: "plain", Location: "plain.jar"},
+		}).Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(layer.Launch).To(BeTrue())
+
+		b, err := os.ReadFile(filepath.Join(layer.Path, "licenses.json"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var report []map[string]interface{}
+		Expect(json.Unmarshal(b, &report)).To(Succeed())
+		Expect(report).To(Equal([]map[string]interface{}{
+			{"purl": "pkg:maven/org.example/lib@1.0", "location": "lib.jar", "sha256": "abc", "licenses": []interface{}{"MIT"}},
+			{"purl": "pkg:maven/plain", "location": "plain.jar", "licenses": []interface{}{}},
+		}))
+	})
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/libpak"
+)
+
+var stopSignal = regexp.MustCompile(`^(SIG)?[A-Z0-9+-]+$`)
+
+// LiveReload is the $BP_LIVE_RELOAD_* configuration of the reload process.
+type LiveReload struct {
+	// WatchPaths are the paths to watch, relative to the application root. If empty, the classpath directories are
+	// watched.
+	WatchPaths []string
+
+	// Ignore are glob patterns of paths to ignore.
+	Ignore []string
+
+	// GitIgnore enables honouring <APPLICATION_ROOT>/.gitignore.
+	GitIgnore bool
+
+	// Debounce is the time to wait for further changes before restarting, in milliseconds with the unit, such as 500ms.
+	Debounce string
+
+	// StopSignal is the signal sent to the process to stop it.
+	StopSignal string
+
+	// StopTimeout is the time to wait for the process to stop before killing it, in milliseconds with the unit, such as
+	// 10000ms.
+	StopTimeout string
+
+	// ClearScreen clears the screen before each restart.
+	ClearScreen bool
+
+	// PostRestart is a shell command run each time the process has been (re)started, alongside the process.
+	PostRestart string
+
+	// Permissions are the mode bits added to the watched files, none if zero.
+	Permissions fs.FileMode
+}
+
+// NewLiveReload reads the live reload configuration, returning an error for invalid values.
+func NewLiveReload(cr libpak.ConfigurationResolver) (LiveReload, error) {
+	l := LiveReload{
+		GitIgnore:   true,
+		Permissions: DefaultPermissions,
+		ClearScreen: cr.ResolveBool("BP_LIVE_RELOAD_CLEAR_SCREEN"),
+	}
+
+	if s, _ := cr.Resolve("BP_LIVE_RELOAD_GITIGNORE"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return LiveReload{}, fmt.Errorf("unable to parse $BP_LIVE_RELOAD_GITIGNORE %q\n%w", s, err)
+		}
+		l.GitIgnore = b
+	}
+
+	s, _ := cr.Resolve("BP_LIVE_RELOAD_WATCH_PATHS")
+	l.WatchPaths = splitList(s)
+
+	s, _ = cr.Resolve("BP_LIVE_RELOAD_IGNORE")
+	l.Ignore = splitList(s)
+
+	// durations are always passed on with a unit, as watchexec reads a number as milliseconds for --debounce but as
+	// seconds for --stop-timeout
+	for _, d := range []struct {
+		name  string
+		unit  time.Duration
+		units string
+		value *string
+	}{
+		{"BP_LIVE_RELOAD_DEBOUNCE", time.Millisecond, "milliseconds", &l.Debounce},
+		{"BP_LIVE_RELOAD_STOP_TIMEOUT", time.Second, "seconds", &l.StopTimeout},
+	} {
+		s, _ := cr.Resolve(d.name)
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+
+		v, err := time.ParseDuration(s)
+		if n, nErr := strconv.ParseUint(s, 10, 32); nErr == nil {
+			v, err = time.Duration(n)*d.unit, nil
+		}
+		if err != nil || v < 0 {
+			return LiveReload{}, fmt.Errorf("$%s must be a number of %s or a duration such as 500ms, not %q", d.name, d.units, s)
+		}
+		*d.value = fmt.Sprintf("%dms", v.Milliseconds())
+	}
+
+	if s, _ := cr.Resolve("BP_LIVE_RELOAD_STOP_SIGNAL"); s != "" {
+		if !stopSignal.MatchString(s) {
+			return LiveReload{}, fmt.Errorf("$BP_LIVE_RELOAD_STOP_SIGNAL must be a signal name such as SIGTERM, not %q", s)
+		}
+		l.StopSignal = s
+	}
+
+	l.PostRestart, _ = cr.Resolve("BP_LIVE_RELOAD_POST_RESTART")
+
+	if s, _ := cr.Resolve("BP_LIVE_RELOAD_PERMISSIONS"); s != "" {
+		m, err := strconv.ParseUint(s, 8, 32)
+		if err != nil || m > 0777 {
+			return LiveReload{}, fmt.Errorf("$BP_LIVE_RELOAD_PERMISSIONS must be octal mode bits such as 0060, not %q", s)
+		}
+		l.Permissions = fs.FileMode(m)
+	}
+
+	return l, nil
+}
+
+// Arguments returns the watchexec arguments that run command with arguments. classPath are the classpath entries of
+// the application, used as the default watch paths.
+func (l LiveReload) Arguments(appPath string, classPath []string, command string, arguments []string) []string {
+	args := []string{"-r"}
+
+	if l.PostRestart == "" {
+		args = append(args, "--shell=none")
+	} else {
+		args = append(args, "--shell=sh")
+	}
+
+	for _, p := range l.Watched(appPath, classPath) {
+		args = append(args, fmt.Sprintf("--watch=%s", p))
+	}
+
+	for _, i := range l.Ignore {
+		args = append(args, fmt.Sprintf("--ignore=%s", i))
+	}
+
+	if l.GitIgnore {
+		if f := filepath.Join(appPath, ".gitignore"); isFile(f) {
+			args = append(args, fmt.Sprintf("--ignore-file=%s", f))
+		}
+	} else {
+		args = append(args, "--no-vcs-ignore")
+	}
+
+	if l.Debounce != "" {
+		args = append(args, fmt.Sprintf("--debounce=%s", l.Debounce))
+	}
+
+	if l.StopSignal != "" {
+		args = append(args, fmt.Sprintf("--stop-signal=%s", l.StopSignal))
+	}
+
+	if l.StopTimeout != "" {
+		args = append(args, fmt.Sprintf("--stop-timeout=%s", l.StopTimeout))
+	}
+
+	if l.ClearScreen {
+		args = append(args, "--clear")
+	}
+
+	args = append(args, "--")
+
+	if l.PostRestart == "" {
+		return append(append(args, command), arguments...)
+	}
+
+	return append(args, l.postRestartScript(command, arguments))
+}
+
+// postRestartScript returns a shell script that starts command with arguments in the background, runs PostRestart once
+// it has started and then waits for the command. The signals that stop the process are forwarded to the command so
+// that the watcher does not restart it before it has stopped.
+func (l LiveReload) postRestartScript(command string, arguments []string) string {
+	s := []string{shellQuote(command)}
+	for _, a := range arguments {
+		s = append(s, shellQuote(a))
+	}
+
+	script := []string{fmt.Sprintf("%s & pid=$!", strings.Join(s, " "))}
+
+	signals := []string{"TERM", "INT"}
+	if n := strings.TrimPrefix(l.StopSignal, "SIG"); n != "" && n != "TERM" && n != "INT" {
+		signals = append([]string{n}, signals...)
+	}
+	for _, n := range signals {
+		script = append(script, fmt.Sprintf(`trap 'kill -s %[1]s "$pid"; wait "$pid"; exit $?' %[1]s`, n))
+	}
+
+	return strings.Join(append(script, fmt.Sprintf("(%s)", l.PostRestart), `wait "$pid"`), "; ")
+}
+
+// Watched returns the absolute paths to watch, the configured watch paths or the directories on the classpath if none
+// are configured.
+func (l LiveReload) Watched(appPath string, classPath []string) []string {
+	var paths []string
+
+	if len(l.WatchPaths) > 0 {
+		for _, p := range l.WatchPaths {
+			paths = append(paths, resolvePath(appPath, p))
+		}
+		return paths
+	}
+
+	for _, p := range classPath {
+		p = resolvePath(appPath, p)
+		if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+			paths = append(paths, p)
+		}
+	}
+
+	return paths
+}
+
+func resolvePath(appPath string, p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(appPath, p)
+}
+
+func isFile(p string) bool {
+	fi, err := os.Stat(p)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// splitList splits a comma or whitespace separated list.
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// shellQuote quotes s for use as a single word in a POSIX shell command.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
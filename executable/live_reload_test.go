@@ -0,0 +1,235 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testLiveReload(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath string
+		cr      libpak.ConfigurationResolver
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+		Expect(os.MkdirAll(filepath.Join(appPath, "classes"), 0755)).To(Succeed())
+	})
+
+	arguments := func() []string {
+		l, err := executable.NewLiveReload(cr)
+		Expect(err).NotTo(HaveOccurred())
+
+		return l.Arguments(appPath, []string{appPath, "classes", "lib/a.jar"}, "java", []string{"Main"})
+	}
+
+	it("watches the classpath directories by default", func() {
+		Expect(arguments()).To(Equal([]string{
+			"-r", "--shell=none",
+			"--watch=" + appPath,
+			"--watch=" + filepath.Join(appPath, "classes"),
+			"--", "java", "Main",
+		}))
+	})
+
+	it("watches $BP_LIVE_RELOAD_WATCH_PATHS", func() {
+		t.Setenv("BP_LIVE_RELOAD_WATCH_PATHS", "src, /opt/config")
+
+		Expect(arguments()).To(Equal([]string{
+			"-r", "--shell=none",
+			"--watch=" + filepath.Join(appPath, "src"),
+			"--watch=/opt/config",
+			"--", "java", "Main",
+		}))
+	})
+
+	it("ignores $BP_LIVE_RELOAD_IGNORE", func() {
+		t.Setenv("BP_LIVE_RELOAD_IGNORE", "*.log,tmp/**")
+
+		Expect(arguments()).To(ContainElements("--ignore=*.log", "--ignore=tmp/**"))
+	})
+
+	context(".gitignore", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(appPath, ".gitignore"), []byte("*.log"), 0644)).To(Succeed())
+		})
+
+		it("honours .gitignore", func() {
+			Expect(arguments()).To(ContainElement("--ignore-file=" + filepath.Join(appPath, ".gitignore")))
+		})
+
+		it("does not honour .gitignore if $BP_LIVE_RELOAD_GITIGNORE is false", func() {
+			t.Setenv("BP_LIVE_RELOAD_GITIGNORE", "false")
+
+			Expect(arguments()).NotTo(ContainElement(HavePrefix("--ignore-file")))
+			Expect(arguments()).To(ContainElement("--no-vcs-ignore"))
+		})
+
+		it("fails if $BP_LIVE_RELOAD_GITIGNORE is not a bool", func() {
+			t.Setenv("BP_LIVE_RELOAD_GITIGNORE", "sometimes")
+
+			_, err := executable.NewLiveReload(cr)
+			Expect(err).To(MatchError(ContainSubstring("BP_LIVE_RELOAD_GITIGNORE")))
+		})
+	})
+
+	context("$BP_LIVE_RELOAD_DEBOUNCE", func() {
+		it("accepts a duration", func() {
+			t.Setenv("BP_LIVE_RELOAD_DEBOUNCE", "500ms")
+
+			Expect(arguments()).To(ContainElement("--debounce=500ms"))
+		})
+
+		it("accepts milliseconds", func() {
+			t.Setenv("BP_LIVE_RELOAD_DEBOUNCE", "250")
+
+			Expect(arguments()).To(ContainElement("--debounce=250ms"))
+		})
+
+		it("fails for anything else", func() {
+			t.Setenv("BP_LIVE_RELOAD_DEBOUNCE", "soon")
+
+			_, err := executable.NewLiveReload(cr)
+			Expect(err).To(MatchError(ContainSubstring("$BP_LIVE_RELOAD_DEBOUNCE must be")))
+		})
+	})
+
+	context("$BP_LIVE_RELOAD_STOP_SIGNAL", func() {
+		it("sets the stop signal", func() {
+			t.Setenv("BP_LIVE_RELOAD_STOP_SIGNAL", "SIGINT")
+
+			Expect(arguments()).To(ContainElement("--stop-signal=SIGINT"))
+		})
+
+		it("fails for an invalid signal", func() {
+			t.Setenv("BP_LIVE_RELOAD_STOP_SIGNAL", "kill -9")
+
+			_, err := executable.NewLiveReload(cr)
+			Expect(err).To(MatchError(ContainSubstring("$BP_LIVE_RELOAD_STOP_SIGNAL must be")))
+		})
+	})
+
+	context("$BP_LIVE_RELOAD_STOP_TIMEOUT", func() {
+		it("sets the stop timeout", func() {
+			t.Setenv("BP_LIVE_RELOAD_STOP_TIMEOUT", "10s")
+
+			Expect(arguments()).To(ContainElement("--stop-timeout=10000ms"))
+		})
+
+		it("accepts seconds", func() {
+			t.Setenv("BP_LIVE_RELOAD_STOP_TIMEOUT", "3")
+
+			Expect(arguments()).To(ContainElement("--stop-timeout=3000ms"))
+		})
+
+		it("fails for an invalid timeout", func() {
+			t.Setenv("BP_LIVE_RELOAD_STOP_TIMEOUT", "-")
+
+			_, err := executable.NewLiveReload(cr)
+			Expect(err).To(MatchError(ContainSubstring("$BP_LIVE_RELOAD_STOP_TIMEOUT must be")))
+		})
+	})
+
+	context("$BP_LIVE_RELOAD_PERMISSIONS", func() {
+		it("defaults to group read-write", func() {
+			l, err := executable.NewLiveReload(cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(l.Permissions).To(Equal(os.FileMode(0060)))
+		})
+
+		it("sets the permissions", func() {
+			t.Setenv("BP_LIVE_RELOAD_PERMISSIONS", "0066")
+
+			l, err := executable.NewLiveReload(cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(l.Permissions).To(Equal(os.FileMode(0066)))
+		})
+
+		it("fails for invalid permissions", func() {
+			for _, s := range []string{"g+rw", "0999", "01777"} {
+				t.Setenv("BP_LIVE_RELOAD_PERMISSIONS", s)
+
+				_, err := executable.NewLiveReload(cr)
+				Expect(err).To(MatchError(ContainSubstring("$BP_LIVE_RELOAD_PERMISSIONS must be")))
+			}
+		})
+	})
+
+	it("clears the screen if $BP_LIVE_RELOAD_CLEAR_SCREEN is true", func() {
+		t.Setenv("BP_LIVE_RELOAD_CLEAR_SCREEN", "true")
+
+		Expect(arguments()).To(ContainElement("--clear"))
+	})
+
+	context("$BP_LIVE_RELOAD_POST_RESTART", func() {
+		it.Before(func() {
+			t.Setenv("BP_LIVE_RELOAD_POST_RESTART", "echo restarted")
+		})
+
+		it("runs the command after the process has started", func() {
+			l, err := executable.NewLiveReload(cr)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(l.Arguments(appPath, []string{appPath}, "java", []string{"-Dgreeting=hello world", "Main"})).To(Equal([]string{
+				"-r", "--shell=sh",
+				"--watch=" + appPath,
+				"--", "java '-Dgreeting=hello world' Main & pid=$!; " +
+					`trap 'kill -s TERM "$pid"; wait "$pid"; exit $?' TERM; ` +
+					`trap 'kill -s INT "$pid"; wait "$pid"; exit $?' INT; ` +
+					`(echo restarted); wait "$pid"`,
+			}))
+		})
+
+		it("forwards the stop signal to the process", func() {
+			t.Setenv("BP_LIVE_RELOAD_STOP_SIGNAL", "SIGHUP")
+
+			args := arguments()
+			Expect(args[len(args)-1]).To(HavePrefix(`java Main & pid=$!; trap 'kill -s HUP "$pid"; wait "$pid"; exit $?' HUP; `))
+		})
+
+		it("runs in a shell that exits with the status of the process", func() {
+			if _, err := exec.LookPath("sh"); err != nil {
+				t.Skip("sh is not available")
+			}
+
+			l, err := executable.NewLiveReload(cr)
+			Expect(err).NotTo(HaveOccurred())
+			l.PostRestart = "echo hook >> out"
+
+			args := l.Arguments(appPath, nil, "sh", []string{"-c", "echo process >> out; sleep 0.2; exit 3"})
+			cmd := exec.Command("sh", "-c", args[len(args)-1])
+			cmd.Dir = appPath
+			err = cmd.Run()
+			Expect(err).To(BeAssignableToTypeOf(&exec.ExitError{}))
+			Expect(err.(*exec.ExitError).ExitCode()).To(Equal(3))
+
+			Expect(os.ReadFile(filepath.Join(appPath, "out"))).To(ContainSubstring("hook"))
+		})
+	})
+}
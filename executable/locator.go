@@ -0,0 +1,356 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/magiconair/properties"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/internal/fsutil"
+)
+
+// ErrSkipLocators is returned by a Locator to indicate that the candidates it found are authoritative and that the
+// remaining Locators should not be run.
+var ErrSkipLocators = errors.New("skip remaining locators")
+
+// LocatorContext is the application and configuration passed to each Locator.
+type LocatorContext struct {
+	// FS is the application to search.
+	FS fs.FS
+
+	// ExecutableJARGlob is the configured $BP_EXECUTABLE_JAR_LOCATION.
+	ExecutableJARGlob string
+
+	// MainClass is the configured $BP_EXECUTABLE_JAR_MAIN_CLASS.
+	MainClass string
+
+	// Exclude are the configured $BP_EXECUTABLE_JAR_EXCLUDE glob patterns. JAR files whose path or name matches one of
+	// them are not candidates.
+	Exclude []string
+
+	// StrictLocation makes a glob that matches more than one executable JAR an error wrapping ErrAmbiguousJAR. By
+	// default the first match is used and the others are logged as a warning.
+	StrictLocation bool
+
+	// Logger logs the warnings of FindExecutableJAR.
+	Logger bard.Logger
+}
+
+// NewLocatorContext creates a LocatorContext from the buildpack configuration. FS is left for the caller to set.
+func NewLocatorContext(cr libpak.ConfigurationResolver) LocatorContext {
+	glob, _ := cr.Resolve("BP_EXECUTABLE_JAR_LOCATION")
+	mainClass, _ := cr.Resolve("BP_EXECUTABLE_JAR_MAIN_CLASS")
+	exclude, _ := cr.Resolve("BP_EXECUTABLE_JAR_EXCLUDE")
+
+	return LocatorContext{ExecutableJARGlob: glob, MainClass: mainClass, Exclude: splitList(exclude)}
+}
+
+// Locator is a strategy for finding executable JARs in an application.
+type Locator interface {
+
+	// Name returns the name of the Locator, used in logs and as the Candidate's Locator.
+	Name() string
+
+	// Locate passes each candidate it finds to yield, in rank order, stopping early if yield returns false. It returns
+	// ErrSkipLocators if no further Locators should be run.
+	Locate(context LocatorContext, yield func(Candidate) bool) error
+}
+
+var (
+	locatorsMutex sync.Mutex
+	locators      = []Locator{
+		MainClassLocator{},
+		ExplodedJARLocator{},
+		GlobLocator{},
+		JARScanLocator{},
+		DistributionLocator{},
+	}
+)
+
+// DefaultLocators returns the registered Locators in the order that Detect and Build run them.
+func DefaultLocators() []Locator {
+	locatorsMutex.Lock()
+	defer locatorsMutex.Unlock()
+
+	return append([]Locator{}, locators...)
+}
+
+// RegisterLocator adds l to the DefaultLocators, directly ahead of the Locator named before. If before is empty or
+// is not registered, l is run after all other Locators.
+func RegisterLocator(l Locator, before string) {
+	locatorsMutex.Lock()
+	defer locatorsMutex.Unlock()
+
+	for i, r := range locators {
+		if before != "" && r.Name() == before {
+			locators = append(locators[:i], append([]Locator{l}, locators[i:]...)...)
+			return
+		}
+	}
+
+	locators = append(locators, l)
+}
+
+// MainClassLocator uses the application root as an exploded JAR when a main class is configured explicitly. The
+// classpath is taken from the Class-Path of <APPLICATION_ROOT>/META-INF/MANIFEST.MF if there is one, otherwise it
+// is made up of the JAR files in <APPLICATION_ROOT> and <APPLICATION_ROOT>/lib.
+type MainClassLocator struct{}
+
+func (MainClassLocator) Name() string {
+	return "main-class"
+}
+
+func (MainClassLocator) Locate(context LocatorContext, yield func(Candidate) bool) error {
+	if context.MainClass == "" {
+		return nil
+	}
+
+	props, err := NewManifestFS(context.FS, ".")
+	if err != nil {
+		return fmt.Errorf("unable to parse manifest\n%w", err)
+	}
+
+	if _, ok := props.Get("Class-Path"); !ok {
+		var cp []string
+		for _, dir := range []string{".", "lib"} {
+			jars, err := fs.Glob(context.FS, path.Join(dir, "*.jar"))
+			if err != nil {
+				return fmt.Errorf("unable to list JARs in %s\n%w", dir, err)
+			}
+			cp = append(cp, jars...)
+		}
+		if len(cp) > 0 {
+			props.Set("Class-Path", strings.Join(cp, " "))
+		}
+	}
+	props.Set("Main-Class", context.MainClass)
+
+	yield(Candidate{Path: ".", ExplodedJAR: true, MainClass: context.MainClass, Properties: props})
+	return ErrSkipLocators
+}
+
+// ExplodedJARLocator finds an exploded JAR at the application root, indicated by a META-INF/MANIFEST.MF. If there is
+// one it is the only candidate, even if it has no Main-Class.
+type ExplodedJARLocator struct{}
+
+func (ExplodedJARLocator) Name() string {
+	return "exploded-jar"
+}
+
+func (ExplodedJARLocator) Locate(context LocatorContext, yield func(Candidate) bool) error {
+	_, err := fs.Stat(context.FS, "META-INF/MANIFEST.MF")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read manifest.mf\n%w", err)
+	}
+
+	c, err := newCandidate(".", true, false, func() (*properties.Properties, error) {
+		return NewManifestFS(context.FS, ".")
+	})
+	if err != nil {
+		return err
+	}
+
+	yield(c)
+	return ErrSkipLocators
+}
+
+// GlobLocator finds the JAR files matching the configured executable JAR glob, in lexical order.
+type GlobLocator struct{}
+
+func (GlobLocator) Name() string {
+	return "glob"
+}
+
+func (GlobLocator) Locate(context LocatorContext, yield func(Candidate) bool) error {
+	if context.ExecutableJARGlob == "" {
+		return nil
+	}
+
+	files, _ := fs.Glob(context.FS, strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(context.ExecutableJARGlob)), "/"))
+	for _, f := range files {
+		fi, err := fs.Stat(context.FS, f)
+		if err != nil {
+			return err
+		}
+
+		if ok, err := yieldJAR(context.FS, f, fs.FileInfoToDirEntry(fi), true, yield); err != nil || !ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DistributionLocator finds applications laid out as a distribution, with launch scripts in bin and JAR files in
+// lib, such as those created by the Gradle application plugin. The main class and classpath are read from the
+// launch scripts. Distributions at the application root and directly below it are found. It runs after the
+// JARScanLocator, so that a distribution is only used if none of its JAR files is executable.
+type DistributionLocator struct{}
+
+var (
+	distributionMainClass = regexp.MustCompile(`(?m)-classpath\s+"\$CLASSPATH"\s*\\?\s*([\p{L}_$][\p{L}\p{N}_$.]*)`)
+	distributionClassPath = regexp.MustCompile(`(?m)^CLASSPATH=(.+)$`)
+)
+
+func (DistributionLocator) Name() string {
+	return "distribution"
+}
+
+func (DistributionLocator) Locate(context LocatorContext, yield func(Candidate) bool) error {
+	dirs := []string{"."}
+	entries, err := fs.ReadDir(context.FS, ".")
+	if err != nil {
+		return fmt.Errorf("unable to read application\n%w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, e.Name())
+		}
+	}
+
+	for _, dir := range dirs {
+		if !isDir(context.FS, path.Join(dir, "bin")) || !isDir(context.FS, path.Join(dir, "lib")) {
+			continue
+		}
+
+		scripts, err := fs.ReadDir(context.FS, path.Join(dir, "bin"))
+		if err != nil {
+			return fmt.Errorf("unable to read %s\n%w", path.Join(dir, "bin"), err)
+		}
+
+		for _, s := range scripts {
+			if s.IsDir() || strings.HasSuffix(s.Name(), ".bat") {
+				continue
+			}
+
+			b, err := fs.ReadFile(context.FS, path.Join(dir, "bin", s.Name()))
+			if err != nil {
+				return fmt.Errorf("unable to read %s\n%w", path.Join(dir, "bin", s.Name()), err)
+			}
+
+			m := distributionMainClass.FindSubmatch(b)
+			if m == nil {
+				continue
+			}
+
+			var cp []string
+			if c := distributionClassPath.FindSubmatch(b); c != nil {
+				for _, e := range strings.Split(strings.Trim(string(c[1]), `"`), ":") {
+					if e = strings.TrimPrefix(e, "$APP_HOME/"); e != "" {
+						cp = append(cp, path.Join(dir, e))
+					}
+				}
+			} else if cp, err = fs.Glob(context.FS, path.Join(dir, "lib", "*.jar")); err != nil {
+				return fmt.Errorf("unable to list JARs in %s\n%w", path.Join(dir, "lib"), err)
+			}
+
+			props := properties.NewProperties()
+			props.Set("Main-Class", string(m[1]))
+			if len(cp) > 0 {
+				props.Set("Class-Path", strings.Join(cp, " "))
+			}
+
+			if !yield(Candidate{Path: ".", ExplodedJAR: true, MainClass: string(m[1]), Properties: props}) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// JARScanLocator searches the whole application breadth-first for JAR files, following symbolic links.
+type JARScanLocator struct{}
+
+func (JARScanLocator) Name() string {
+	return "jar-scan"
+}
+
+func (JARScanLocator) Locate(context LocatorContext, yield func(Candidate) bool) error {
+	stopWalk := errors.New("stop walking")
+
+	err := fsutil.WalkDir(context.FS, ".", fsutil.WalkOptions{Symlinks: fsutil.SymlinkFollow}, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if ok, err := yieldJAR(context.FS, name, d, false, yield); err != nil {
+			return err
+		} else if !ok {
+			return stopWalk
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, stopWalk) {
+		return err
+	}
+
+	return nil
+}
+
+// yieldJAR passes the JAR file at name to yield, returning whether to continue. Anything that is not a JAR file is
+// skipped.
+func yieldJAR(fsys fs.FS, name string, d fs.DirEntry, matched bool, yield func(Candidate) bool) (bool, error) {
+	// make sure it is a file, symbolic links have already been resolved where possible
+	if !d.Type().IsRegular() {
+		return true, nil
+	}
+
+	// make sure it is a JAR file
+	if !strings.HasSuffix(name, ".jar") {
+		return true, nil
+	}
+
+	c, err := newCandidate(name, false, matched, func() (*properties.Properties, error) {
+		return NewManifestFromJARFS(fsys, name)
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to load manifest\n%w", err)
+	}
+
+	return yield(c), nil
+}
+
+func isDir(fsys fs.FS, name string) bool {
+	fi, err := fs.Stat(fsys, name)
+	return err == nil && fi.IsDir()
+}
+
+// excluded reports whether the JAR file at name matches one of the Exclude patterns.
+func (l LocatorContext) excluded(name string) bool {
+	for _, pattern := range l.Exclude {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
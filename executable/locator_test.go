@@ -0,0 +1,228 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+// fakeLocator yields a fixed candidate, like an in-house packaging format would.
+type fakeLocator struct {
+	name      string
+	candidate executable.Candidate
+}
+
+func (t fakeLocator) Name() string {
+	return t.name
+}
+
+func (t fakeLocator) Locate(_ executable.LocatorContext, yield func(executable.Candidate) bool) error {
+	yield(t.candidate)
+	return nil
+}
+
+func testLocator(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("DefaultLocators", func() {
+		it("runs the built-in locators in order", func() {
+			var names []string
+			for _, l := range executable.DefaultLocators() {
+				names = append(names, l.Name())
+			}
+
+			Expect(names).To(Equal([]string{"main-class", "exploded-jar", "glob", "jar-scan", "distribution"}))
+		})
+	})
+
+	context("RegisterLocator", func() {
+		it("adds a locator ahead of the named one", func() {
+			original := executable.DefaultLocators()
+
+			executable.RegisterLocator(fakeLocator{name: "in-house"}, "distribution")
+			executable.RegisterLocator(fakeLocator{name: "last"}, "")
+			defer func() {
+				executable.ResetLocators(original)
+			}()
+
+			var names []string
+			for _, l := range executable.DefaultLocators() {
+				names = append(names, l.Name())
+			}
+
+			Expect(names).To(Equal([]string{"main-class", "exploded-jar", "glob", "jar-scan", "in-house", "distribution", "last"}))
+		})
+	})
+
+	context("FindExecutableJAR", func() {
+		it("uses the first executable candidate", func() {
+			ej, err := executable.FindExecutableJAR(executable.LocatorContext{FS: fstest.MapFS{}}, []executable.Locator{
+				fakeLocator{name: "rejected", candidate: executable.Candidate{Path: "a.bin", Rejection: executable.ErrNoMainClass}},
+				fakeLocator{name: "in-house", candidate: executable.Candidate{Path: "b.bin", MainClass: "B"}},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ej.Path).To(Equal("b.bin"))
+			Expect(ej.MainClass).To(Equal("B"))
+			Expect(ej.Locator).To(Equal("in-house"))
+		})
+
+		it("skips excluded JAR files", func() {
+			fsys := fstest.MapFS{
+				"lib/tools.jar":   {Data: JARBytes(t, map[string]string{"Main-Class": "Tools"})},
+				"app-sources.jar": {Data: JARBytes(t, map[string]string{"Main-Class": "Sources"})},
+				"lib/app.jar":     {Data: JARBytes(t, map[string]string{"Main-Class": "App"})},
+			}
+
+			ej, err := executable.FindExecutableJAR(executable.LocatorContext{FS: fsys, Exclude: []string{"*-sources.jar", "lib/tools.jar"}}, executable.DefaultLocators())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ej.Path).To(Equal("lib/app.jar"))
+		})
+	})
+
+	context("MainClassLocator", func() {
+		it("does nothing without a configured main class", func() {
+			candidates, err := executable.FindCandidates(executable.LocatorContext{FS: fstest.MapFS{}}, []executable.Locator{
+				executable.MainClassLocator{},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(candidates).To(BeEmpty())
+		})
+
+		it("uses JAR files in the root and lib as the classpath", func() {
+			candidates, err := executable.FindCandidates(executable.LocatorContext{
+				FS: fstest.MapFS{
+					"app.jar":      {Data: JARBytes(t, map[string]string{"Main-Class": "Other"})},
+					"lib/dep.jar":  {Data: JARBytes(t, nil)},
+					"lib/notes.md": {},
+				},
+				MainClass: "com.example.Main",
+			}, executable.DefaultLocators())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(candidates).To(HaveLen(1))
+			Expect(candidates[0].ExplodedJAR).To(BeTrue())
+			Expect(candidates[0].MainClass).To(Equal("com.example.Main"))
+			Expect(candidates[0].Properties.Map()).To(Equal(map[string]string{
+				"Main-Class": "com.example.Main",
+				"Class-Path": "app.jar lib/dep.jar",
+			}))
+		})
+
+		it("overrides the Main-Class of an exploded JAR", func() {
+			candidates, err := executable.FindCandidates(executable.LocatorContext{
+				FS: fstest.MapFS{
+					"META-INF/MANIFEST.MF": {Data: []byte("Main-Class: Foo\nClass-Path: a.jar")},
+				},
+				MainClass: "Bar",
+			}, executable.DefaultLocators())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(candidates).To(HaveLen(1))
+			Expect(candidates[0].Locator).To(Equal("main-class"))
+			Expect(candidates[0].Properties.Map()).To(Equal(map[string]string{
+				"Main-Class": "Bar",
+				"Class-Path": "a.jar",
+			}))
+		})
+	})
+
+	context("DistributionLocator", func() {
+		it("reads the main class and classpath from a Gradle start script", func() {
+			candidates, err := executable.FindCandidates(executable.LocatorContext{
+				FS: fstest.MapFS{
+					"demo/bin/demo": {Data: []byte(`#!/bin/sh
+CLASSPATH=$APP_HOME/lib/demo.jar:$APP_HOME/lib/guava.jar
+
+set -- \
+        "-Dorg.gradle.appname=$APP_BASE_NAME" \
+        -classpath "$CLASSPATH" \
+        com.example.App \
+        "$@"
+`)},
+					"demo/bin/demo.bat":  {Data: []byte(`"%JAVA_EXE%" -classpath "%CLASSPATH%" com.example.App %*`)},
+					"demo/lib/demo.jar":  {Data: JARBytes(t, nil)},
+					"demo/lib/guava.jar": {Data: JARBytes(t, nil)},
+				},
+			}, []executable.Locator{executable.DistributionLocator{}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(candidates).To(HaveLen(1))
+			Expect(candidates[0].ExplodedJAR).To(BeTrue())
+			Expect(candidates[0].MainClass).To(Equal("com.example.App"))
+			Expect(candidates[0].Properties.Map()).To(HaveKeyWithValue("Class-Path", "demo/lib/demo.jar demo/lib/guava.jar"))
+		})
+
+		it("is only used if no JAR file is executable", func() {
+			fsys := fstest.MapFS{
+				"bin/app":     {Data: []byte("#!/bin/sh\nexec java -classpath \"$CLASSPATH\" com.example.Script \"$@\"\n")},
+				"lib/app.jar": {Data: JARBytes(t, map[string]string{"Main-Class": "com.example.Main"})},
+			}
+
+			ej, err := executable.FindExecutableJAR(executable.LocatorContext{FS: fsys}, executable.DefaultLocators())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ej.Locator).To(Equal("jar-scan"))
+			Expect(ej.MainClass).To(Equal("com.example.Main"))
+
+			fsys["lib/app.jar"] = &fstest.MapFile{Data: JARBytes(t, nil)}
+			ej, err = executable.FindExecutableJAR(executable.LocatorContext{FS: fsys}, executable.DefaultLocators())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ej.Locator).To(Equal("distribution"))
+			Expect(ej.MainClass).To(Equal("com.example.Script"))
+		})
+
+		it("finds each of the distributions", func() {
+			candidates, err := executable.FindCandidates(executable.LocatorContext{
+				FS: fstest.MapFS{
+					"bin/app":           {Data: []byte("#!/bin/sh\nexec java -classpath \"$CLASSPATH\" com.example.App \"$@\"\n")},
+					"bin/worker":        {Data: []byte("#!/bin/sh\nexec java -classpath \"$CLASSPATH\" com.example.Worker \"$@\"\n")},
+					"lib/app.jar":       {Data: JARBytes(t, nil)},
+					"demo/bin/demo":     {Data: []byte("#!/bin/sh\nexec java -classpath \"$CLASSPATH\" com.example.Demo \"$@\"\n")},
+					"demo/lib/demo.jar": {Data: JARBytes(t, nil)},
+				},
+			}, []executable.Locator{executable.DistributionLocator{}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(candidates).To(HaveLen(3))
+			Expect(candidates[0].MainClass).To(Equal("com.example.App"))
+			Expect(candidates[1].MainClass).To(Equal("com.example.Worker"))
+			Expect(candidates[2].MainClass).To(Equal("com.example.Demo"))
+			Expect(candidates[2].Properties.Map()).To(HaveKeyWithValue("Class-Path", "demo/lib/demo.jar"))
+		})
+
+		it("ignores directories without a start script", func() {
+			candidates, err := executable.FindCandidates(executable.LocatorContext{
+				FS: fstest.MapFS{
+					"bin/README":  {Data: []byte("nothing to see")},
+					"lib/app.jar": {Data: JARBytes(t, nil)},
+				},
+			}, []executable.Locator{executable.DistributionLocator{}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(candidates).To(BeEmpty())
+		})
+	})
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"strings"
+)
+
+// mavenQualifiers ranks the well-known qualifiers of Maven versions. A version without a qualifier is a release.
+var mavenQualifiers = map[string]int{
+	"alpha":     1,
+	"a":         1,
+	"beta":      2,
+	"b":         2,
+	"milestone": 3,
+	"m":         3,
+	"rc":        4,
+	"cr":        4,
+	"snapshot":  5,
+	"":          6,
+	"ga":        6,
+	"final":     6,
+	"release":   6,
+	"sp":        7,
+}
+
+// mavenVersionItem is a number or a qualifier of a Maven version.
+type mavenVersionItem struct {
+	number    string
+	qualifier string
+	numeric   bool
+}
+
+// CompareMavenVersions compares two Maven versions in the way Maven orders them, returning -1, 0 or 1. Versions are
+// split into numbers and qualifiers at dots, hyphens and changes between digits and letters, so that 2.0-beta9 is
+// before 2.0 and 2.0 is the same as 2.0.0. Well-known qualifiers such as rc and snapshot are before a release and
+// unknown qualifiers are after it.
+func CompareMavenVersions(a string, b string) int {
+	x, y := mavenVersionItems(a), mavenVersionItems(b)
+
+	for i := 0; i < len(x) || i < len(y); i++ {
+		var p, q mavenVersionItem
+		if i < len(x) {
+			p = x[i]
+		} else {
+			p = mavenVersionItem{numeric: y[i].numeric}
+		}
+		if i < len(y) {
+			q = y[i]
+		} else {
+			q = mavenVersionItem{numeric: x[i].numeric}
+		}
+
+		if c := p.compare(q); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func (m mavenVersionItem) compare(o mavenVersionItem) int {
+	switch {
+	case m.numeric && o.numeric:
+		if len(m.number) != len(o.number) {
+			return compareInts(len(m.number), len(o.number))
+		}
+		return strings.Compare(m.number, o.number)
+	case m.numeric:
+		return 1
+	case o.numeric:
+		return -1
+	}
+
+	r, ok := mavenQualifiers[m.qualifier]
+	s, ok2 := mavenQualifiers[o.qualifier]
+	switch {
+	case ok && ok2:
+		return compareInts(r, s)
+	case ok:
+		return -1
+	case ok2:
+		return 1
+	default:
+		return strings.Compare(m.qualifier, o.qualifier)
+	}
+}
+
+// mavenVersionItems splits a version into its items, dropping the zeros that end each hyphen separated part.
+func mavenVersionItems(version string) []mavenVersionItem {
+	var items []mavenVersionItem
+
+	for _, part := range strings.Split(strings.ToLower(version), "-") {
+		var p []mavenVersionItem
+
+		for _, s := range strings.Split(part, ".") {
+			start := 0
+			for i := 1; i <= len(s); i++ {
+				if i < len(s) && isDigit(s[i]) == isDigit(s[start]) {
+					continue
+				}
+
+				if isDigit(s[start]) {
+					n := strings.TrimLeft(s[start:i], "0")
+					p = append(p, mavenVersionItem{number: n, numeric: true})
+				} else {
+					p = append(p, mavenVersionItem{qualifier: s[start:i]})
+				}
+				start = i
+			}
+		}
+
+		for len(p) > 0 {
+			last := p[len(p)-1]
+			if (last.numeric && last.number != "") || (!last.numeric && mavenQualifiers[last.qualifier] != 6) {
+				break
+			}
+			p = p[:len(p)-1]
+		}
+
+		items = append(items, p...)
+	}
+
+	return items
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
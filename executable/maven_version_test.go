@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testMavenVersion(t *testing.T, _ spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	it("orders versions", func() {
+		ordered := []string{
+			"1.0-alpha1",
+			"1.0-beta2",
+			"1.0-M3",
+			"1.0-RC1",
+			"1.0-SNAPSHOT",
+			"1.0",
+			"1.0-sp1",
+			"1.0-jre",
+			"1.0.1",
+			"1.2",
+			"1.10",
+			"2.0-beta9",
+			"2.0",
+			"2.15.0",
+		}
+
+		for i := range ordered {
+			for j := range ordered {
+				c := 0
+				if i < j {
+					c = -1
+				} else if i > j {
+					c = 1
+				}
+				Expect(executable.CompareMavenVersions(ordered[i], ordered[j])).To(Equal(c), "%s and %s", ordered[i], ordered[j])
+			}
+		}
+	})
+
+	it("ignores trailing zeros and release qualifiers", func() {
+		Expect(executable.CompareMavenVersions("2.15", "2.15.0")).To(Equal(0))
+		Expect(executable.CompareMavenVersions("1.0-rc1", "1-rc1")).To(Equal(0))
+		Expect(executable.CompareMavenVersions("5.6.15.Final", "5.6.15")).To(Equal(0))
+		Expect(executable.CompareMavenVersions("1.010", "1.10")).To(Equal(0))
+	})
+}
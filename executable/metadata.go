@@ -0,0 +1,244 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	PackageModeJAR      = "jar"
+	PackageModeExploded = "exploded"
+)
+
+// PackageManifestAttributes are the manifest attributes copied into PackageMetadata when present.
+var PackageManifestAttributes = []string{
+	"Add-Exports",
+	"Add-Opens",
+	"Automatic-Module-Name",
+	"Build-Jdk-Spec",
+	"Created-By",
+	"Implementation-Title",
+	"Implementation-Vendor",
+	"Implementation-Version",
+	"Launcher-Agent-Class",
+	"Multi-Release",
+	"Spring-Boot-Version",
+	"Start-Class",
+}
+
+// PackageMetadata describes the executable JAR chosen during detection. It is attached to the jvm-application-package
+// plan entry so that later buildpacks do not need to search the application again. The build plan schema only allows
+// metadata on requirements, so it is found on this buildpack's requirement rather than its provision. See README.md for
+// the schema.
+type PackageMetadata struct {
+	// Path is the slash-separated path of the JAR file relative to the application root, "." for an exploded JAR.
+	Path string
+
+	// Mode is either PackageModeJAR or PackageModeExploded.
+	Mode string
+
+	// MainClass is the main class that is run.
+	MainClass string
+
+	// ClassPath contains the entries of the Class-Path manifest attribute.
+	ClassPath []string
+
+	// JavaVersion is the Java version the main class was compiled for, e.g. "17", or empty if unknown.
+	JavaVersion string
+
+	// Locator is the name of the Locator that found the executable JAR.
+	Locator string
+
+	// Manifest contains the PackageManifestAttributes present in the manifest.
+	Manifest map[string]string
+}
+
+// NewPackageMetadata describes execJar, found in the application at appPath.
+func NewPackageMetadata(appPath string, execJar ExecutableJAR) PackageMetadata {
+	m := PackageMetadata{
+		Path:      ".",
+		Mode:      PackageModeExploded,
+		MainClass: execJar.MainClass,
+		Locator:   execJar.Locator,
+		Manifest:  map[string]string{},
+	}
+
+	if !execJar.ExplodedJAR {
+		m.Mode = PackageModeJAR
+		if rel, err := filepath.Rel(appPath, execJar.Path); err == nil {
+			m.Path = filepath.ToSlash(rel)
+		} else {
+			m.Path = execJar.Path
+		}
+	}
+
+	if execJar.Properties != nil {
+		if s, ok := execJar.Properties.Get("Class-Path"); ok {
+			m.ClassPath = strings.Fields(s)
+		}
+		for _, k := range PackageManifestAttributes {
+			if v, ok := execJar.Properties.Get(k); ok {
+				m.Manifest[k] = v
+			}
+		}
+	}
+
+	m.JavaVersion = javaVersion(os.DirFS(appPath), m)
+
+	return m
+}
+
+// Metadata returns the plan entry metadata.
+func (p PackageMetadata) Metadata() map[string]interface{} {
+	md := map[string]interface{}{
+		"path":       p.Path,
+		"mode":       p.Mode,
+		"main-class": p.MainClass,
+	}
+
+	if len(p.ClassPath) > 0 {
+		md["class-path"] = p.ClassPath
+	}
+	if p.JavaVersion != "" {
+		md["java-version"] = p.JavaVersion
+	}
+	if p.Locator != "" {
+		md["locator"] = p.Locator
+	}
+	if len(p.Manifest) > 0 {
+		md["manifest"] = p.Manifest
+	}
+
+	return md
+}
+
+// ParsePackageMetadata reads PackageMetadata from plan entry metadata, as decoded from the build plan.
+func ParsePackageMetadata(metadata map[string]interface{}) PackageMetadata {
+	var p PackageMetadata
+
+	p.Path, _ = metadata["path"].(string)
+	p.Mode, _ = metadata["mode"].(string)
+	p.MainClass, _ = metadata["main-class"].(string)
+	p.JavaVersion, _ = metadata["java-version"].(string)
+	p.Locator, _ = metadata["locator"].(string)
+
+	switch cp := metadata["class-path"].(type) {
+	case []string:
+		p.ClassPath = cp
+	case []interface{}:
+		for _, e := range cp {
+			if s, ok := e.(string); ok {
+				p.ClassPath = append(p.ClassPath, s)
+			}
+		}
+	}
+
+	switch m := metadata["manifest"].(type) {
+	case map[string]string:
+		p.Manifest = m
+	case map[string]interface{}:
+		p.Manifest = map[string]string{}
+		for k, v := range m {
+			if s, ok := v.(string); ok {
+				p.Manifest[k] = s
+			}
+		}
+	}
+
+	return p
+}
+
+// javaVersion determines the Java version from the class file of the main class, falling back to the Build-Jdk-Spec
+// manifest attribute. For Spring Boot applications the Start-Class is used, as the Main-Class is a launcher.
+func javaVersion(fsys fs.FS, m PackageMetadata) string {
+	class := m.MainClass
+	prefixes := []string{""}
+	if s, ok := m.Manifest["Start-Class"]; ok {
+		class = s
+		prefixes = append(prefixes, "BOOT-INF/classes/", "WEB-INF/classes/")
+	}
+
+	name := strings.ReplaceAll(class, ".", "/") + ".class"
+	for _, p := range prefixes {
+		var major uint16
+		var err error
+
+		if m.Mode == PackageModeJAR {
+			major, err = classVersionFromJAR(fsys, m.Path, p+name)
+		} else {
+			major, err = classVersion(fsys, path.Join(m.Path, p+name))
+		}
+
+		if err == nil {
+			return javaVersionOf(major)
+		}
+	}
+
+	return m.Manifest["Build-Jdk-Spec"]
+}
+
+func classVersionFromJAR(fsys fs.FS, jar string, name string) (uint16, error) {
+	z, err := openJAR(fsys, jar)
+	if err != nil {
+		return 0, err
+	}
+	defer z.Close()
+
+	return classVersion(z, name)
+}
+
+// classVersion reads the major version from the header of a class file.
+func classVersion(fsys fs.FS, name string) (uint16, error) {
+	in, err := fsys.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	var header struct {
+		Magic uint32
+		Minor uint16
+		Major uint16
+	}
+	if err := binary.Read(io.LimitReader(in, 8), binary.BigEndian, &header); err != nil {
+		return 0, fmt.Errorf("unable to read class file header of %s\n%w", name, err)
+	}
+	if header.Magic != 0xCAFEBABE {
+		return 0, fmt.Errorf("%s is not a class file", name)
+	}
+
+	return header.Major, nil
+}
+
+// javaVersionOf converts a class file major version into a Java version, e.g. 61 into 17.
+func javaVersionOf(major uint16) string {
+	if major < 45 {
+		return ""
+	} else if major < 49 {
+		return fmt.Sprintf("1.%d", major-44)
+	}
+	return strconv.Itoa(int(major) - 44)
+}
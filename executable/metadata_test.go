@@ -0,0 +1,140 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testMetadata(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath string
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+	})
+
+	// class file header for Java 17
+	classFile := []byte{0xCA, 0xFE, 0xBA, 0xBE, 0x00, 0x00, 0x00, 0x3D}
+
+	it("describes an exploded JAR", func() {
+		Expect(os.MkdirAll(filepath.Join(appPath, "com", "example"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "com", "example", "Main.class"), classFile, 0644)).To(Succeed())
+
+		props := properties.NewProperties()
+		props.Set("Main-Class", "com.example.Main")
+		props.Set("Class-Path", "lib/a.jar lib/b.jar")
+		props.Set("Implementation-Version", "1.2.3")
+		props.Set("Manifest-Version", "1.0")
+
+		m := executable.NewPackageMetadata(appPath, executable.ExecutableJAR{
+			MainClass:   "com.example.Main",
+			Path:        appPath,
+			Properties:  props,
+			Executable:  true,
+			ExplodedJAR: true,
+			Locator:     "exploded-jar",
+		})
+
+		Expect(m.Metadata()).To(Equal(map[string]interface{}{
+			"path":         ".",
+			"mode":         "exploded",
+			"main-class":   "com.example.Main",
+			"class-path":   []string{"lib/a.jar", "lib/b.jar"},
+			"java-version": "17",
+			"locator":      "exploded-jar",
+			"manifest":     map[string]string{"Implementation-Version": "1.2.3"},
+		}))
+	})
+
+	it("reads the Java version of the Start-Class in a Spring Boot JAR", func() {
+		Expect(os.MkdirAll(filepath.Join(appPath, "target"), 0755)).To(Succeed())
+		jar := filepath.Join(appPath, "target", "app.jar")
+
+		f, err := os.Create(jar)
+		Expect(err).NotTo(HaveOccurred())
+		z := zip.NewWriter(f)
+		w, err := z.Create("BOOT-INF/classes/com/example/App.class")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = w.Write(classFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(z.Close()).To(Succeed())
+		Expect(f.Close()).To(Succeed())
+
+		props := properties.NewProperties()
+		props.Set("Main-Class", "org.springframework.boot.loader.launch.JarLauncher")
+		props.Set("Start-Class", "com.example.App")
+
+		m := executable.NewPackageMetadata(appPath, executable.ExecutableJAR{
+			MainClass:  "org.springframework.boot.loader.launch.JarLauncher",
+			Path:       jar,
+			Properties: props,
+			Executable: true,
+		})
+
+		Expect(m.Path).To(Equal("target/app.jar"))
+		Expect(m.Mode).To(Equal(executable.PackageModeJAR))
+		Expect(m.JavaVersion).To(Equal("17"))
+	})
+
+	it("falls back to Build-Jdk-Spec", func() {
+		props := properties.NewProperties()
+		props.Set("Main-Class", "com.example.Main")
+		props.Set("Build-Jdk-Spec", "21")
+
+		m := executable.NewPackageMetadata(appPath, executable.ExecutableJAR{
+			MainClass:   "com.example.Main",
+			Path:        appPath,
+			Properties:  props,
+			ExplodedJAR: true,
+		})
+
+		Expect(m.JavaVersion).To(Equal("21"))
+	})
+
+	it("parses metadata decoded from a build plan", func() {
+		m := executable.ParsePackageMetadata(map[string]interface{}{
+			"path":         "app.jar",
+			"mode":         "jar",
+			"main-class":   "com.example.Main",
+			"class-path":   []interface{}{"a.jar"},
+			"java-version": "11",
+			"manifest":     map[string]interface{}{"Start-Class": "com.example.App"},
+		})
+
+		Expect(m).To(Equal(executable.PackageMetadata{
+			Path:        "app.jar",
+			Mode:        "jar",
+			MainClass:   "com.example.Main",
+			ClassPath:   []string{"a.jar"},
+			JavaVersion: "11",
+			Manifest:    map[string]string{"Start-Class": "com.example.App"},
+		}))
+	})
+}
@@ -0,0 +1,343 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/magiconair/properties"
+)
+
+// Package is a Java library found in an application.
+type Package struct {
+	// Group is the Maven group id, empty if it is not known.
+	Group string
+
+	// Name is the Maven artifact id, or the best name available if the library was not built by Maven.
+	Name string
+
+	// Version is the version of the library, empty if it is not known.
+	Version string
+
+	// Location is the slash-separated path of the library relative to the scanned directory, or its absolute path if
+	// it is outside of it. Libraries nested in an archive are located by the path of the archive and the path within
+	// it, separated by a colon.
+	Location string
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the archive containing the library, empty for an exploded JAR.
+	SHA256 string
+
+	// Licenses are the SPDX license identifiers or, if they are not known, the names of the licenses of the library,
+	// empty if none were found. See Inventory for how they are found.
+	Licenses []string
+}
+
+// PURL returns the package URL of the library.
+func (p Package) PURL() string {
+	s := "pkg:maven/"
+	if p.Group != "" {
+		s += p.Group + "/"
+	}
+	s += p.Name
+	if p.Version != "" {
+		s += "@" + p.Version
+	}
+	return s
+}
+
+// Inventory returns the libraries within scanDir and on the runtime classPath of the application, such as Class-Path
+// entries outside of scanDir, sorted by location. Libraries that have the same coordinates and checksum are only
+// listed once, at their first location. If cache is not nil, the packages of unchanged archives are read from it
+// rather than from the archives.
+//
+// Libraries are identified by their META-INF/maven/**/pom.properties, their manifest attributes or, failing both,
+// their file name. JAR and WAR files are searched, including the archives nested within them, and so are the exploded
+// JARs at scanDir and at the directories on classPath. The licenses of a library are those in the pom.xml next to its
+// pom.properties or, if it has none, those of the archive it was found in: the Bundle-License manifest attribute and
+// the licenses identified from the text of the META-INF/LICENSE* files.
+func Inventory(scanDir string, classPath []string, cache *PackageCache) ([]Package, error) {
+	i := newInspector()
+	i.inventory, i.cache = true, cache
+
+	if err := i.walk(scanDir, classPath); err != nil {
+		return nil, fmt.Errorf("unable to find packages\n%w", err)
+	}
+
+	return dedupePackages(i.packages), nil
+}
+
+// dedupePackages sorts packages by location and removes those with the same coordinates and checksum as a package
+// at an earlier location.
+func dedupePackages(packages []Package) []Package {
+	sort.SliceStable(packages, func(i, j int) bool {
+		if packages[i].Location != packages[j].Location {
+			return packages[i].Location < packages[j].Location
+		}
+		return packages[i].PURL() < packages[j].PURL()
+	})
+
+	seen := map[[2]string]bool{}
+	deduped := packages[:0]
+	for _, p := range packages {
+		key := [2]string{p.PURL(), p.SHA256}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, p)
+	}
+
+	return deduped
+}
+
+// packagesInDirectory returns the libraries described by the pom.properties in the META-INF/maven of an exploded JAR
+// at the root of fsys, or by its manifest if there are none.
+func packagesInDirectory(fsys fs.FS) ([]Package, error) {
+	poms, err := fs.Glob(fsys, "META-INF/maven/*/*/pom.properties")
+	if err != nil {
+		return nil, fmt.Errorf("unable to find pom.properties\n%w", err)
+	}
+
+	manifest, err := NewManifestFS(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	licenses := licensesFromBundle(manifest.GetString("Bundle-License", ""))
+	files, err := fs.Glob(fsys, "META-INF/*")
+	if err != nil {
+		return nil, fmt.Errorf("unable to find license files\n%w", err)
+	}
+	for _, f := range files {
+		if fi, err := fs.Stat(fsys, f); err != nil || !isLicenseFile(f) || fi.IsDir() {
+			continue
+		}
+
+		b, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s\n%w", f, err)
+		}
+		if l, ok := licenseFromText(b); ok {
+			licenses = appendLicenses(licenses, l)
+		}
+	}
+
+	var packages []Package
+	for _, p := range poms {
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s\n%w", p, err)
+		}
+
+		if pkg, ok := packageFromPOM(b, "."); ok {
+			pkg.Licenses = licenses
+			if b, err := fs.ReadFile(fsys, path.Join(path.Dir(p), "pom.xml")); err == nil {
+				if l := licensesFromPOM(b); len(l) > 0 {
+					pkg.Licenses = l
+				}
+			}
+			packages = append(packages, pkg)
+		}
+	}
+	if len(packages) > 0 {
+		return packages, nil
+	}
+
+	if pkg, ok := packageFromManifest(manifest, "."); ok {
+		pkg.Licenses = licenses
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// packagesInArchive returns the libraries in the archive z at location with checksum sum, not including those in the
+// archives nested within it.
+func packagesInArchive(z *zip.Reader, location string, sum string) ([]Package, error) {
+	var packages []Package
+	var manifest *properties.Properties
+	var dirs, licenses []string
+	poms := map[string][]string{}
+
+	for _, f := range z.File {
+		switch {
+		case strings.HasPrefix(f.Name, "META-INF/maven/") && path.Base(f.Name) == "pom.properties":
+			b, err := readEntry(f)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read %s in %s\n%w", f.Name, location, err)
+			}
+
+			if pkg, ok := packageFromPOM(b, location); ok {
+				pkg.SHA256 = sum
+				packages = append(packages, pkg)
+				dirs = append(dirs, path.Dir(f.Name))
+			}
+
+		case strings.HasPrefix(f.Name, "META-INF/maven/") && path.Base(f.Name) == "pom.xml":
+			b, err := readEntry(f)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read %s in %s\n%w", f.Name, location, err)
+			}
+			poms[path.Dir(f.Name)] = licensesFromPOM(b)
+
+		case isLicenseFile(f.Name) && !f.FileInfo().IsDir():
+			b, err := readEntry(f)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read %s in %s\n%w", f.Name, location, err)
+			}
+			if l, ok := licenseFromText(b); ok {
+				licenses = appendLicenses(licenses, l)
+			}
+
+		case f.Name == "META-INF/MANIFEST.MF":
+			b, err := readEntry(f)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read %s in %s\n%w", f.Name, location, err)
+			}
+			if manifest, err = parseManifest(b, location); err != nil {
+				return nil, err
+			}
+
+		}
+	}
+
+	if manifest != nil {
+		licenses = appendLicenses(licensesFromBundle(manifest.GetString("Bundle-License", "")), licenses...)
+	}
+	for i, d := range dirs {
+		if l := poms[d]; len(l) > 0 {
+			packages[i].Licenses = l
+		} else if len(licenses) > 0 {
+			packages[i].Licenses = licenses
+		}
+	}
+
+	if len(packages) == 0 && manifest != nil {
+		if pkg, ok := packageFromManifest(manifest, location); ok {
+			pkg.SHA256 = sum
+			packages = append(packages, pkg)
+		}
+	}
+	if len(packages) == 0 {
+		pkg := packageFromFileName(location)
+		pkg.SHA256 = sum
+		packages = append(packages, pkg)
+	}
+	if len(dirs) == 0 && len(licenses) > 0 {
+		packages[0].Licenses = licenses
+	}
+
+	return packages, nil
+}
+
+// packageFromPOM reads the pom.properties Maven writes into the libraries it builds.
+func packageFromPOM(b []byte, location string) (Package, bool) {
+	pom, err := properties.Load(b, properties.UTF8)
+	if err != nil {
+		return Package{}, false
+	}
+
+	pkg := Package{
+		Group:    pom.GetString("groupId", ""),
+		Name:     pom.GetString("artifactId", ""),
+		Version:  pom.GetString("version", ""),
+		Location: location,
+	}
+	return pkg, pkg.Name != ""
+}
+
+// packageFromManifest reads the implementation or, failing that, the OSGi bundle attributes of a manifest.
+func packageFromManifest(manifest *properties.Properties, location string) (Package, bool) {
+	if name, ok := manifest.Get("Implementation-Title"); ok {
+		return Package{
+			Group:    manifest.GetString("Implementation-Vendor-Id", ""),
+			Name:     name,
+			Version:  manifest.GetString("Implementation-Version", ""),
+			Location: location,
+		}, true
+	}
+
+	if name, ok := manifest.Get("Bundle-SymbolicName"); ok {
+		// the symbolic name may be followed by directives such as ;singleton:=true
+		name, _, _ = strings.Cut(name, ";")
+		return Package{
+			Name:     strings.TrimSpace(name),
+			Version:  manifest.GetString("Bundle-Version", ""),
+			Location: location,
+		}, true
+	}
+
+	return Package{}, false
+}
+
+// packageFromFileName splits the file name of an archive, such as commons-lang3-3.12.0.jar, into its name and version.
+func packageFromFileName(location string) Package {
+	name := location
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(path.Base(name), path.Ext(name))
+
+	for i := 0; i < len(name)-1; i++ {
+		if name[i] == '-' && name[i+1] >= '0' && name[i+1] <= '9' {
+			return Package{Name: name[:i], Version: name[i+1:], Location: location}
+		}
+	}
+
+	return Package{Name: name, Location: location}
+}
+
+func isArchive(name string) bool {
+	switch path.Ext(name) {
+	case ".jar", ".war":
+		return true
+	default:
+		return false
+	}
+}
+
+func checksum(fsys fs.FS, name string) (string, error) {
+	in, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, in); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func readEntry(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
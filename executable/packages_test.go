@@ -0,0 +1,171 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testPackages(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath string
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+	})
+
+	// find returns the packages in appPath without their checksums
+	find := func() []executable.Package {
+		packages, err := executable.Inventory(appPath, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := range packages {
+			packages[i].SHA256 = ""
+		}
+		return packages
+	}
+
+	// zipContents returns the contents of a zip file containing entries, for nesting in another zip file
+	zipContents := func(entries map[string]string) string {
+		file := filepath.Join(t.TempDir(), "nested.jar")
+		CreateZIP(t, file, entries)
+
+		b, err := os.ReadFile(file)
+		Expect(err).NotTo(HaveOccurred())
+		return string(b)
+	}
+
+	it("finds the libraries nested in a JAR file", func() {
+		CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+			"META-INF/MANIFEST.MF":                          "Main-Class: test.Main\n",
+			"META-INF/maven/com.example/app/pom.properties": "groupId=com.example\nartifactId=app\nversion=1.0.0\n",
+			"BOOT-INF/lib/commons-lang3-3.12.0.jar": zipContents(map[string]string{
+				"META-INF/maven/org.apache.commons/commons-lang3/pom.properties": "groupId=org.apache.commons\nartifactId=commons-lang3\nversion=3.12.0\n",
+			}),
+			"BOOT-INF/lib/bundle.jar": zipContents(map[string]string{
+				"META-INF/MANIFEST.MF": "Bundle-SymbolicName: org.example.bundle;singleton:=true\nBundle-Version: 2.1.0\n",
+			}),
+			"BOOT-INF/lib/plain-4.2.jar": zipContents(map[string]string{}),
+		})
+
+		Expect(find()).To(Equal([]executable.Package{
+			{Group: "com.example", Name: "app", Version: "1.0.0", Location: "app.jar"},
+			{Name: "org.example.bundle", Version: "2.1.0", Location: "app.jar:BOOT-INF/lib/bundle.jar"},
+			{Group: "org.apache.commons", Name: "commons-lang3", Version: "3.12.0", Location: "app.jar:BOOT-INF/lib/commons-lang3-3.12.0.jar"},
+			{Name: "plain", Version: "4.2", Location: "app.jar:BOOT-INF/lib/plain-4.2.jar"},
+		}))
+	})
+
+	it("finds an exploded JAR and its libraries", func() {
+		Expect(os.MkdirAll(filepath.Join(appPath, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "META-INF", "MANIFEST.MF"),
+			[]byte("Main-Class: test.Main\nImplementation-Title: app\nImplementation-Version: 1.0.0\n"), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(appPath, "lib"), 0755)).To(Succeed())
+		CreateZIP(t, filepath.Join(appPath, "lib", "guava-31.1-jre.jar"), map[string]string{})
+
+		Expect(find()).To(Equal([]executable.Package{
+			{Name: "app", Version: "1.0.0", Location: "."},
+			{Name: "guava", Version: "31.1-jre", Location: "lib/guava-31.1-jre.jar"},
+		}))
+	})
+
+	it("checksums archives", func() {
+		CreateZIP(t, filepath.Join(appPath, "lib-1.0.jar"), map[string]string{})
+		b, err := os.ReadFile(filepath.Join(appPath, "lib-1.0.jar"))
+		Expect(err).NotTo(HaveOccurred())
+
+		packages, err := executable.Inventory(appPath, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(packages).To(ConsistOf(HaveField("SHA256", fmt.Sprintf("%x", sha256.Sum256(b)))))
+	})
+
+	it("lists a library with the same coordinates and checksum once", func() {
+		lib := zipContents(map[string]string{
+			"META-INF/maven/org.example/lib/pom.properties": "groupId=org.example\nartifactId=lib\nversion=1.0\n",
+		})
+		patched := zipContents(map[string]string{
+			"META-INF/maven/org.example/lib/pom.properties": "groupId=org.example\nartifactId=lib\nversion=1.0\n",
+			"org/example/Patched.class":                     "",
+		})
+
+		CreateZIP(t, filepath.Join(appPath, "a.jar"), map[string]string{"BOOT-INF/lib/lib-1.0.jar": lib})
+		CreateZIP(t, filepath.Join(appPath, "b.jar"), map[string]string{"lib/lib-1.0.jar": lib, "lib/patched.jar": patched})
+
+		Expect(find()).To(Equal([]executable.Package{
+			{Name: "a", Location: "a.jar"},
+			{Group: "org.example", Name: "lib", Version: "1.0", Location: "a.jar:BOOT-INF/lib/lib-1.0.jar"},
+			{Name: "b", Location: "b.jar"},
+			{Group: "org.example", Name: "lib", Version: "1.0", Location: "b.jar:lib/patched.jar"},
+		}))
+	})
+
+	context("Inventory", func() {
+		var external string
+
+		it.Before(func() {
+			external = t.TempDir()
+
+			CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+				"META-INF/maven/com.example/app/pom.properties": "groupId=com.example\nartifactId=app\nversion=1.0.0\n",
+			})
+			CreateZIP(t, filepath.Join(external, "shared-2.0.jar"), map[string]string{})
+			Expect(os.MkdirAll(filepath.Join(external, "plugins"), 0755)).To(Succeed())
+			CreateZIP(t, filepath.Join(external, "plugins", "plugin-3.0.jar"), map[string]string{})
+		})
+
+		it("includes the classpath outside of the application", func() {
+			packages, err := executable.Inventory(appPath, []string{
+				filepath.Join(appPath, "app.jar"),
+				filepath.Join(external, "shared-2.0.jar"),
+				filepath.Join(external, "plugins"),
+				filepath.Join(external, "missing.jar"),
+			}, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(packages).To(HaveLen(3))
+			Expect(packages).To(ContainElements(
+				HaveField("Location", "app.jar"),
+				HaveField("Location", filepath.ToSlash(filepath.Join(external, "shared-2.0.jar"))),
+				HaveField("Location", filepath.ToSlash(filepath.Join(external, "plugins", "plugin-3.0.jar"))),
+			))
+		})
+	})
+
+	it("returns an error for an invalid archive", func() {
+		Expect(os.WriteFile(filepath.Join(appPath, "broken.jar"), []byte("not a zip"), 0644)).To(Succeed())
+
+		_, err := executable.Inventory(appPath, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("unable to open broken.jar")))
+	})
+
+	it("creates package URLs", func() {
+		Expect(executable.Package{Group: "org.example", Name: "lib", Version: "1.0"}.PURL()).To(Equal("pkg:maven/org.example/lib@1.0"))
+		Expect(executable.Package{Name: "lib"}.PURL()).To(Equal("pkg:maven/lib"))
+	})
+}
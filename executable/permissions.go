@@ -0,0 +1,189 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/internal/fsutil"
+)
+
+// DefaultPermissions are the mode bits added to watched files for live reload, making them group read-writable.
+const DefaultPermissions fs.FileMode = 0060
+
+// PermissionSummary is the outcome of AddPermissions.
+type PermissionSummary struct {
+	// Changed is the number of files whose mode was changed.
+	Changed int
+
+	// Unchanged is the number of files that already had the mode bits.
+	Unchanged int
+
+	// Skipped is the number of symbolic links and files that could not be read or changed.
+	Skipped int
+
+	// Errors are the reasons files could not be read or changed.
+	Errors []error
+}
+
+func (p PermissionSummary) String() string {
+	return fmt.Sprintf("%d changed, %d unchanged, %d skipped", p.Changed, p.Unchanged, p.Skipped)
+}
+
+// AddPermissions adds mode to the files and directories below each of roots, using workers goroutines, or one per CPU
+// if workers is not positive. Symbolic links are skipped rather than followed, so files outside the roots are never
+// changed, and the roots themselves are left as they are. Files that cannot be changed are counted and the remaining
+// files are still changed.
+func AddPermissions(roots []string, mode fs.FileMode, workers int) PermissionSummary {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var (
+		summary PermissionSummary
+		mutex   sync.Mutex
+		wg      sync.WaitGroup
+	)
+
+	record := func(f func(s *PermissionSummary)) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		f(&summary)
+	}
+
+	type entry struct {
+		path string
+		d    fs.DirEntry
+	}
+
+	entries := make(chan entry, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for e := range entries {
+				changed, err := addPermission(e.path, e.d, mode)
+				record(func(s *PermissionSummary) {
+					if err != nil {
+						s.Skipped++
+						s.Errors = append(s.Errors, err)
+					} else if changed {
+						s.Changed++
+					} else {
+						s.Unchanged++
+					}
+				})
+			}
+		}()
+	}
+
+	for _, root := range uniqueRoots(roots) {
+		skip := func(p string, err error) {
+			record(func(s *PermissionSummary) {
+				s.Skipped++
+				s.Errors = append(s.Errors, fmt.Errorf("unable to read %s\n%w", filepath.Join(root, filepath.FromSlash(p)), err))
+			})
+		}
+
+		opts := fsutil.WalkOptions{
+			OnDirError: func(p string, err error) error {
+				skip(p, err)
+				return nil
+			},
+		}
+
+		err := fsutil.WalkDir(os.DirFS(root), ".", opts, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if p == "." {
+					return err
+				}
+				skip(p, err)
+				return nil
+			}
+			if p == "." {
+				return nil
+			}
+			if d.Type()&fs.ModeSymlink != 0 {
+				record(func(s *PermissionSummary) { s.Skipped++ })
+				return nil
+			}
+
+			entries <- entry{path: filepath.Join(root, filepath.FromSlash(p)), d: d}
+			return nil
+		})
+		if err != nil {
+			skip(".", err)
+		}
+	}
+
+	close(entries)
+	wg.Wait()
+
+	return summary
+}
+
+// addPermission adds mode to the file at path, returning whether it was changed.
+func addPermission(path string, d fs.DirEntry, mode fs.FileMode) (bool, error) {
+	fi, err := d.Info()
+	if err != nil {
+		return false, fmt.Errorf("unable to stat %s\n%w", path, err)
+	}
+
+	if fi.Mode().Perm()&mode == mode {
+		return false, nil
+	}
+
+	if err := os.Chmod(path, fi.Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky)|mode); err != nil {
+		return false, fmt.Errorf("unable to change mode of %s\n%w", path, err)
+	}
+
+	return true, nil
+}
+
+// uniqueRoots removes roots that are contained by other roots, so that no file is visited twice.
+func uniqueRoots(roots []string) []string {
+	var unique []string
+
+	for i, r := range roots {
+		r = filepath.Clean(r)
+
+		contained := false
+		for j, o := range roots {
+			o = filepath.Clean(o)
+			if i == j {
+				continue
+			}
+			if (r == o && j < i) || (r != o && strings.HasPrefix(r, strings.TrimSuffix(o, string(filepath.Separator))+string(filepath.Separator))) {
+				contained = true
+				break
+			}
+		}
+
+		if !contained {
+			unique = append(unique, r)
+		}
+	}
+
+	return unique
+}
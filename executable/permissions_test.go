@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testPermissions(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	mode := func(p string) os.FileMode {
+		fi, err := os.Lstat(filepath.Join(path, p))
+		Expect(err).NotTo(HaveOccurred())
+		return fi.Mode().Perm()
+	}
+
+	it.Before(func() {
+		path = t.TempDir()
+
+		Expect(os.MkdirAll(filepath.Join(path, "app", "classes", "com"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "app", "classes", "com", "A.class"), []byte{}, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "app", "classes", "B.class"), []byte{}, 0644)).To(Succeed())
+		Expect(os.Chmod(filepath.Join(path, "app", "classes", "B.class"), 0664)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(path, "app", "lib"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "app", "lib", "a.jar"), []byte{}, 0644)).To(Succeed())
+
+		Expect(os.MkdirAll(filepath.Join(path, "outside"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "outside", "secret"), []byte{}, 0600)).To(Succeed())
+		Expect(os.Symlink(filepath.Join(path, "outside"), filepath.Join(path, "app", "classes", "outside"))).To(Succeed())
+		Expect(os.Symlink(filepath.Join(path, "outside", "secret"), filepath.Join(path, "app", "classes", "secret"))).To(Succeed())
+	})
+
+	it("adds permissions below the roots", func() {
+		summary := executable.AddPermissions([]string{filepath.Join(path, "app", "classes")}, 0060, 2)
+
+		Expect(mode("app/classes")).To(Equal(os.FileMode(0755)))
+		Expect(mode("app/classes/com")).To(Equal(os.FileMode(0775)))
+		Expect(mode("app/classes/com/A.class")).To(Equal(os.FileMode(0664)))
+		Expect(mode("app/classes/B.class")).To(Equal(os.FileMode(0664)))
+		Expect(mode("app/lib/a.jar")).To(Equal(os.FileMode(0644)))
+
+		Expect(summary).To(Equal(executable.PermissionSummary{Changed: 2, Unchanged: 1, Skipped: 2}))
+		Expect(summary.String()).To(Equal("2 changed, 1 unchanged, 2 skipped"))
+	})
+
+	it("does not follow symbolic links", func() {
+		executable.AddPermissions([]string{filepath.Join(path, "app")}, 0060, 0)
+
+		Expect(mode("outside")).To(Equal(os.FileMode(0755)))
+		Expect(mode("outside/secret")).To(Equal(os.FileMode(0600)))
+	})
+
+	it("adds the configured mode bits", func() {
+		executable.AddPermissions([]string{filepath.Join(path, "app")}, 0006, 0)
+
+		Expect(mode("app/classes/com/A.class")).To(Equal(os.FileMode(0646)))
+		Expect(mode("app/lib/a.jar")).To(Equal(os.FileMode(0646)))
+	})
+
+	it("visits files below nested roots once", func() {
+		summary := executable.AddPermissions([]string{
+			filepath.Join(path, "app", "classes"),
+			filepath.Join(path, "app"),
+			filepath.Join(path, "app") + string(filepath.Separator),
+		}, 0060, 0)
+
+		Expect(summary).To(Equal(executable.PermissionSummary{Changed: 5, Unchanged: 1, Skipped: 2}))
+	})
+
+	it("reports roots that cannot be read", func() {
+		summary := executable.AddPermissions([]string{filepath.Join(path, "missing")}, 0060, 0)
+
+		Expect(summary.Skipped).To(Equal(1))
+		Expect(summary.Errors).To(ConsistOf(MatchError(ContainSubstring("unable to read"))))
+	})
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+)
+
+var processType = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ProcessDefinition is a process declared with $BP_EXECUTABLE_JAR_PROCESSES.
+type ProcessDefinition struct {
+	// Type is the process type.
+	Type string
+
+	// JVMOptions are passed to the JVM ahead of the JAR file or main class.
+	JVMOptions []string
+
+	// Arguments are passed to the application.
+	Arguments []string
+}
+
+// NewProcessDefinitions reads the processes named by $BP_EXECUTABLE_JAR_PROCESSES. The JVM options and application
+// arguments of each are read from $BP_EXECUTABLE_JAR_PROCESS_<TYPE>_JVM_OPTIONS and
+// $BP_EXECUTABLE_JAR_PROCESS_<TYPE>_ARGS, where <TYPE> is the upper case type with . and - replaced by _. Types that
+// share variables, such as a-b and a.b, are rejected.
+func NewProcessDefinitions(cr libpak.ConfigurationResolver) ([]ProcessDefinition, error) {
+	var definitions []ProcessDefinition
+	types := map[string]string{}
+
+	s, _ := cr.Resolve("BP_EXECUTABLE_JAR_PROCESSES")
+	for _, t := range splitList(s) {
+		if !processType.MatchString(t) {
+			return nil, fmt.Errorf("$BP_EXECUTABLE_JAR_PROCESSES contains invalid process type %q", t)
+		}
+
+		v := processVariable(t)
+		if o, ok := types[v]; ok {
+			return nil, fmt.Errorf("$BP_EXECUTABLE_JAR_PROCESSES contains process types %q and %q which both read $BP_EXECUTABLE_JAR_PROCESS_%s_*", o, t, v)
+		}
+		types[v] = t
+
+		d := ProcessDefinition{Type: t}
+		prefix := fmt.Sprintf("BP_EXECUTABLE_JAR_PROCESS_%s", v)
+
+		for _, f := range []struct {
+			suffix string
+			value  *[]string
+		}{
+			{"_JVM_OPTIONS", &d.JVMOptions},
+			{"_ARGS", &d.Arguments},
+		} {
+			s, _ := cr.Resolve(prefix + f.suffix)
+
+			var err error
+			if *f.value, err = splitArguments(s); err != nil {
+				return nil, fmt.Errorf("unable to parse $%s%s\n%w", prefix, f.suffix, err)
+			}
+		}
+
+		definitions = append(definitions, d)
+	}
+
+	return definitions, nil
+}
+
+// Process returns the process that runs command with launch, the JAR file or main class arguments, between the JVM
+// options and the application arguments.
+func (p ProcessDefinition) Process(command string, launch []string) libcnb.Process {
+	var arguments []string
+	arguments = append(arguments, p.JVMOptions...)
+	arguments = append(arguments, launch...)
+	arguments = append(arguments, p.Arguments...)
+
+	return libcnb.Process{
+		Type:      p.Type,
+		Command:   command,
+		Arguments: arguments,
+		Direct:    true,
+	}
+}
+
+func processVariable(processType string) string {
+	return strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(processType))
+}
+
+// splitArguments splits s into words like a POSIX shell, honouring single quotes, double quotes and backslashes but
+// not expanding anything.
+func splitArguments(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, s)
+	} else if escaped {
+		return nil, fmt.Errorf("trailing backslash in %q", s)
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
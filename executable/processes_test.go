@@ -0,0 +1,92 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testProcesses(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr libpak.ConfigurationResolver
+	)
+
+	it("defines no processes by default", func() {
+		Expect(executable.NewProcessDefinitions(cr)).To(BeEmpty())
+	})
+
+	it("reads the options and arguments of each process", func() {
+		t.Setenv("BP_EXECUTABLE_JAR_PROCESSES", "worker, db-migrate")
+		t.Setenv("BP_EXECUTABLE_JAR_PROCESS_WORKER_JVM_OPTIONS", "-Xmx1g -Dgreeting='hello world'")
+		t.Setenv("BP_EXECUTABLE_JAR_PROCESS_WORKER_ARGS", `--queue "jobs high" --verbose`)
+		t.Setenv("BP_EXECUTABLE_JAR_PROCESS_DB_MIGRATE_ARGS", `migrate\ up`)
+
+		Expect(executable.NewProcessDefinitions(cr)).To(Equal([]executable.ProcessDefinition{
+			{
+				Type:       "worker",
+				JVMOptions: []string{"-Xmx1g", "-Dgreeting=hello world"},
+				Arguments:  []string{"--queue", "jobs high", "--verbose"},
+			},
+			{
+				Type:      "db-migrate",
+				Arguments: []string{"migrate up"},
+			},
+		}))
+	})
+
+	it("fails for an invalid process type", func() {
+		t.Setenv("BP_EXECUTABLE_JAR_PROCESSES", "worker/1")
+
+		_, err := executable.NewProcessDefinitions(cr)
+		Expect(err).To(MatchError(ContainSubstring(`invalid process type "worker/1"`)))
+	})
+
+	it("fails for process types that share variables", func() {
+		t.Setenv("BP_EXECUTABLE_JAR_PROCESSES", "db-migrate,worker,db.migrate")
+
+		_, err := executable.NewProcessDefinitions(cr)
+		Expect(err).To(MatchError(ContainSubstring(`process types "db-migrate" and "db.migrate" which both read $BP_EXECUTABLE_JAR_PROCESS_DB_MIGRATE_*`)))
+	})
+
+	it("fails for unterminated quotes", func() {
+		t.Setenv("BP_EXECUTABLE_JAR_PROCESSES", "worker")
+		t.Setenv("BP_EXECUTABLE_JAR_PROCESS_WORKER_ARGS", `--name "worker`)
+
+		_, err := executable.NewProcessDefinitions(cr)
+		Expect(err).To(MatchError(ContainSubstring("unable to parse $BP_EXECUTABLE_JAR_PROCESS_WORKER_ARGS")))
+	})
+
+	it("creates a process around the launch arguments", func() {
+		d := executable.ProcessDefinition{Type: "worker", JVMOptions: []string{"-Xmx1g"}, Arguments: []string{"--verbose"}}
+
+		Expect(d.Process("java", []string{"-jar", "app.jar"})).To(Equal(libcnb.Process{
+			Type:      "worker",
+			Command:   "java",
+			Arguments: []string{"-Xmx1g", "-jar", "app.jar", "--verbose"},
+			Direct:    true,
+		}))
+	})
+}
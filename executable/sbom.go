@@ -0,0 +1,386 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sbom"
+)
+
+// The values of $BP_EXECUTABLE_JAR_SBOM, the scanner that writes the launch SBOM.
+const (
+	SBOMSyft   = "syft"
+	SBOMNative = "native"
+	SBOMNone   = "none"
+)
+
+// NativeSBOMScanner is an sbom.SBOMScanner that identifies the Java libraries in a directory without running syft,
+// see Inventory.
+type NativeSBOMScanner struct {
+	Layers libcnb.Layers
+	Logger bard.Logger
+
+	// ClassPath is the runtime classpath of the application, whose entries outside of the scanned directory are also
+	// scanned, see Inventory.
+	ClassPath []string
+
+	// Cache holds the packages found in each archive by earlier scans, nil to read every archive.
+	Cache *PackageCache
+
+	// Packages are the libraries already found with Inventory, nil to find them when scanning.
+	Packages []Package
+}
+
+func NewNativeSBOMScanner(layers libcnb.Layers, logger bard.Logger) NativeSBOMScanner {
+	return NativeSBOMScanner{Layers: layers, Logger: logger}
+}
+
+// ScanLayer writes the SBOM of scanDir to the layer SBOM files in the given formats.
+func (n NativeSBOMScanner) ScanLayer(layer libcnb.Layer, scanDir string, formats ...libcnb.SBOMFormat) error {
+	return n.scan(layer.SBOMPath, scanDir, formats...)
+}
+
+// ScanBuild writes the SBOM of scanDir to the build SBOM files in the given formats.
+func (n NativeSBOMScanner) ScanBuild(scanDir string, formats ...libcnb.SBOMFormat) error {
+	return n.scan(n.Layers.BuildSBOMPath, scanDir, formats...)
+}
+
+// ScanLaunch writes the SBOM of scanDir to the launch SBOM files in the given formats.
+func (n NativeSBOMScanner) ScanLaunch(scanDir string, formats ...libcnb.SBOMFormat) error {
+	return n.scan(n.Layers.LaunchSBOMPath, scanDir, formats...)
+}
+
+func (n NativeSBOMScanner) scan(sbomPath func(libcnb.SBOMFormat) string, scanDir string, formats ...libcnb.SBOMFormat) error {
+	var err error
+	packages := n.Packages
+	if packages == nil {
+		if packages, err = Inventory(scanDir, n.ClassPath, n.Cache); err != nil {
+			return fmt.Errorf("unable to scan %s\n%w", scanDir, err)
+		}
+	}
+	n.Logger.Debugf("Found %d packages in %s", len(packages), scanDir)
+
+	for _, format := range formats {
+		var b []byte
+
+		switch format {
+		case libcnb.CycloneDXJSON:
+			b, err = NewCycloneDX(packages)
+		case libcnb.SPDXJSON:
+			b, err = NewSPDX(scanDir, packages)
+		case libcnb.SyftJSON:
+			b, err = NewSyftJSON(scanDir, packages)
+		default:
+			return fmt.Errorf("unsupported SBOM format %s", format.MediaType())
+		}
+		if err != nil {
+			return fmt.Errorf("unable to create %s SBOM\n%w", format, err)
+		}
+
+		if err := os.WriteFile(sbomPath(format), b, 0644); err != nil {
+			return fmt.Errorf("unable to write %s\n%w", sbomPath(format), err)
+		}
+	}
+
+	return nil
+}
+
+// NewSyftJSON returns the Syft JSON document listing packages, in the form libpak writes for dependencies.
+func NewSyftJSON(scanDir string, packages []Package) ([]byte, error) {
+	var artifacts []sbom.SyftArtifact
+	for _, p := range packages {
+		a, err := p.syftArtifact()
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, a)
+	}
+
+	return json.Marshal(sbom.NewSyftDependency(scanDir, artifacts))
+}
+
+type cycloneDX struct {
+	BOMFormat   string `json:"bomFormat"`
+	SpecVersion string `json:"specVersion"`
+	Version     int    `json:"version"`
+	Metadata    struct {
+		Tools []cycloneDXTool `json:"tools"`
+	} `json:"metadata"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXTool struct {
+	Vendor string `json:"vendor"`
+	Name   string `json:"name"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef     string              `json:"bom-ref"`
+	Type       string              `json:"type"`
+	Group      string              `json:"group,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	PURL       string              `json:"purl"`
+	Hashes     []cycloneDXHash     `json:"hashes,omitempty"`
+	Licenses   []cycloneDXLicense  `json:"licenses,omitempty"`
+	Properties []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	License struct {
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name,omitempty"`
+	} `json:"license"`
+}
+
+type cycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewCycloneDX returns the CycloneDX JSON document listing packages. Like the documents written by the syft scanner it
+// has no serial number or timestamp, so that it is reproducible.
+func NewCycloneDX(packages []Package) ([]byte, error) {
+	doc := cycloneDX{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Components:  []cycloneDXComponent{},
+	}
+	doc.Metadata.Tools = []cycloneDXTool{{Vendor: "paketo-buildpacks", Name: "executable-jar"}}
+
+	for _, p := range packages {
+		a, err := p.syftArtifact()
+		if err != nil {
+			return nil, err
+		}
+
+		c := cycloneDXComponent{
+			BOMRef:     a.ID,
+			Type:       "library",
+			Group:      p.Group,
+			Name:       p.Name,
+			Version:    p.Version,
+			PURL:       p.PURL(),
+			Properties: []cycloneDXProperty{{Name: "syft:location:0:path", Value: p.Location}},
+		}
+		if p.SHA256 != "" {
+			c.Hashes = []cycloneDXHash{{Algorithm: "SHA-256", Content: p.SHA256}}
+		}
+		for _, l := range p.Licenses {
+			var license cycloneDXLicense
+			if isSPDXLicense(l) {
+				license.License.ID = l
+			} else {
+				license.License.Name = l
+			}
+			c.Licenses = append(c.Licenses, license)
+		}
+
+		doc.Components = append(doc.Components, c)
+	}
+
+	return json.Marshal(doc)
+}
+
+type spdx struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DataLicense       string `json:"dataLicense"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	CreationInfo      struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	Packages      []spdxPackage      `json:"packages"`
+	Relationships []spdxRelationship `json:"relationships"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	SourceInfo       string            `json:"sourceInfo"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const (
+	// spdxCreated is the creation time of SPDX documents, fixed like the file times in an image.
+	spdxCreated = "1980-01-01T00:00:01Z"
+
+	// spdxNamespace is the prefix of the namespaces of SPDX documents, followed by a checksum of their contents.
+	spdxNamespace = "https://paketo.io/spdx/executable-jar/"
+)
+
+// NewSPDX returns the SPDX JSON document listing packages. SPDX requires a creation time and a unique namespace, so
+// that it is reproducible the creation time is fixed, like the file times in an image, and the namespace is derived
+// from the packages.
+func NewSPDX(scanDir string, packages []Package) ([]byte, error) {
+	b, err := json.Marshal(packages)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := spdx{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              scanDir,
+		DocumentNamespace: fmt.Sprintf("%s%x", spdxNamespace, sha256.Sum256(b)),
+		Packages:          []spdxPackage{},
+		Relationships:     []spdxRelationship{},
+	}
+	doc.CreationInfo.Created = spdxCreated
+	doc.CreationInfo.Creators = []string{"Tool: paketo-buildpacks/executable-jar"}
+
+	for _, p := range packages {
+		a, err := p.syftArtifact()
+		if err != nil {
+			return nil, err
+		}
+
+		s := spdxPackage{
+			SPDXID:           "SPDXRef-Package-" + a.ID,
+			Name:             p.Name,
+			VersionInfo:      p.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+			SourceInfo:       "found at " + p.Location,
+			ExternalRefs: []spdxExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: p.PURL()},
+			},
+		}
+		if p.SHA256 != "" {
+			s.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: p.SHA256}}
+		}
+		if l, ok := spdxLicenseExpression(p.Licenses); ok {
+			s.LicenseDeclared = l
+		}
+
+		doc.Packages = append(doc.Packages, s)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: s.SPDXID,
+		})
+	}
+
+	return json.Marshal(doc)
+}
+
+func (p Package) syftArtifact() (sbom.SyftArtifact, error) {
+	a := sbom.SyftArtifact{
+		Name:      p.Name,
+		Version:   p.Version,
+		Type:      "java-archive",
+		FoundBy:   "executable-jar",
+		Locations: []sbom.SyftLocation{{Path: p.Location}},
+		Licenses:  p.Licenses,
+		Language:  "java",
+		PURL:      p.PURL(),
+	}
+
+	id, err := a.Hash()
+	if err != nil {
+		return sbom.SyftArtifact{}, err
+	}
+	a.ID = id
+
+	return a, nil
+}
+
+// SBOMFormats maps the names accepted by $BP_EXECUTABLE_JAR_SBOM_FORMATS to the SBOM formats. Every format must be
+// declared in the sbom-formats of buildpack.toml.
+var SBOMFormats = map[string]libcnb.SBOMFormat{
+	"cyclonedx": libcnb.CycloneDXJSON,
+	"spdx":      libcnb.SPDXJSON,
+	"syft":      libcnb.SyftJSON,
+}
+
+// resolveSBOMFormats returns the formats listed by $BP_EXECUTABLE_JAR_SBOM_FORMATS, in order and without duplicates.
+func resolveSBOMFormats(cr libpak.ConfigurationResolver) ([]libcnb.SBOMFormat, error) {
+	s, _ := cr.Resolve("BP_EXECUTABLE_JAR_SBOM_FORMATS")
+	if s == "" {
+		s = "syft,cyclonedx"
+	}
+
+	var formats []libcnb.SBOMFormat
+	seen := map[libcnb.SBOMFormat]bool{}
+	for _, n := range strings.Split(s, ",") {
+		f, ok := SBOMFormats[strings.ToLower(strings.TrimSpace(n))]
+		if !ok {
+			return nil, fmt.Errorf("$BP_EXECUTABLE_JAR_SBOM_FORMATS must be a comma separated list of cyclonedx, spdx and syft, not %q", s)
+		}
+		if !seen[f] {
+			seen[f] = true
+			formats = append(formats, f)
+		}
+	}
+
+	return formats, nil
+}
+
+// resolveSBOM returns the configured $BP_EXECUTABLE_JAR_SBOM.
+func resolveSBOM(cr libpak.ConfigurationResolver) (string, error) {
+	switch s, _ := cr.Resolve("BP_EXECUTABLE_JAR_SBOM"); s {
+	case "", SBOMSyft:
+		return SBOMSyft, nil
+	case SBOMNative, SBOMNone:
+		return s, nil
+	default:
+		return "", fmt.Errorf("$BP_EXECUTABLE_JAR_SBOM must be %s, %s or %s, not %q", SBOMSyft, SBOMNative, SBOMNone, s)
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+)
+
+// PackageCache stores the packages found in each archive in a directory, keyed by the SHA-256 checksum of the
+// archive, so that only new and changed archives are read. The packages are the same as those found by reading the
+// archive again. It also stores the parts of the Syft JSON documents that describe each archive, see SyftSBOMScanner.
+type PackageCache struct {
+	// Path is the directory the packages are stored in.
+	Path string
+
+	// Hits is the number of archives whose packages were read from the cache.
+	Hits int
+
+	// Misses is the number of archives that were read because they are not in the cache.
+	Misses int
+
+	used map[string]bool
+}
+
+// packageCacheVersion is the version of packageCacheEntry, increased whenever the packages found in an archive change
+// so that entries stored by earlier versions are read again.
+const packageCacheVersion = 2
+
+// packageCacheEntry is the JSON form of the packages of an archive. Locations are relative to the archive.
+type packageCacheEntry struct {
+	Version int `json:"version"`
+
+	// File is the name of the archive, which names its package if nothing else does.
+	File     string    `json:"file"`
+	Packages []Package `json:"packages"`
+}
+
+func NewPackageCache(path string) *PackageCache {
+	return &PackageCache{Path: path, used: map[string]bool{}}
+}
+
+// get returns the packages of the archive at location with checksum sum.
+func (c *PackageCache) get(sum string, location string) ([]Package, bool) {
+	b, err := os.ReadFile(c.file(sum))
+	if err != nil {
+		c.Misses++
+		return nil, false
+	}
+
+	var entry packageCacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil || entry.Version != packageCacheVersion || entry.File != path.Base(location) {
+		c.Misses++
+		return nil, false
+	}
+
+	for i := range entry.Packages {
+		entry.Packages[i].Location = location + entry.Packages[i].Location
+	}
+
+	c.Hits++
+	c.used[filepath.Base(c.file(sum))] = true
+	return entry.Packages, true
+}
+
+// put stores the packages of the archive at location with checksum sum.
+func (c *PackageCache) put(sum string, location string, packages []Package) error {
+	entry := packageCacheEntry{Version: packageCacheVersion, File: path.Base(location)}
+	for _, p := range packages {
+		p.Location = strings.TrimPrefix(p.Location, location)
+		entry.Packages = append(entry.Packages, p)
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to encode packages of %s\n%w", location, err)
+	}
+
+	if err := os.MkdirAll(c.Path, 0755); err != nil {
+		return fmt.Errorf("unable to create %s\n%w", c.Path, err)
+	}
+	if err := os.WriteFile(c.file(sum), b, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", c.file(sum), err)
+	}
+
+	c.used[filepath.Base(c.file(sum))] = true
+	return nil
+}
+
+// syftCacheVersion is the version of syftFragment, increased whenever the fragments of an archive change so that
+// entries stored by earlier versions are scanned again.
+const syftCacheVersion = 1
+
+// syftFragment is the part of a Syft JSON document that describes an archive and the archives nested within it.
+type syftFragment struct {
+	Version int `json:"version"`
+
+	// File is the path of the archive relative to the scanned directory, which is part of the locations and therefore
+	// of the identifiers in the fragment.
+	File          string            `json:"file"`
+	Artifacts     []json.RawMessage `json:"artifacts"`
+	Relationships []json.RawMessage `json:"artifactRelationships"`
+	Files         []json.RawMessage `json:"files"`
+}
+
+// getSyft returns the Syft JSON fragment of the archive at location with checksum sum.
+func (c *PackageCache) getSyft(sum string, location string) (syftFragment, bool) {
+	b, err := os.ReadFile(c.syftFile(sum, location))
+	if err != nil {
+		return syftFragment{}, false
+	}
+
+	var f syftFragment
+	if err := json.Unmarshal(b, &f); err != nil || f.Version != syftCacheVersion || f.File != location {
+		return syftFragment{}, false
+	}
+
+	c.used[filepath.Base(c.syftFile(sum, location))] = true
+	return f, true
+}
+
+// putSyft stores the Syft JSON fragment of the archive at location with checksum sum.
+func (c *PackageCache) putSyft(sum string, location string, f syftFragment) error {
+	f.Version, f.File = syftCacheVersion, location
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("unable to encode syft fragment of %s\n%w", location, err)
+	}
+
+	if err := os.MkdirAll(c.Path, 0755); err != nil {
+		return fmt.Errorf("unable to create %s\n%w", c.Path, err)
+	}
+	if err := os.WriteFile(c.syftFile(sum, location), b, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", c.syftFile(sum, location), err)
+	}
+
+	c.used[filepath.Base(c.syftFile(sum, location))] = true
+	return nil
+}
+
+// Prune removes the packages and Syft JSON fragments of archives that have not been read from or stored in the cache
+// since it was created.
+func (c *PackageCache) Prune() error {
+	files, err := os.ReadDir(c.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to list %s\n%w", c.Path, err)
+	}
+
+	for _, f := range files {
+		if c.used[f.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.Path, f.Name())); err != nil {
+			return fmt.Errorf("unable to remove %s\n%w", f.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func (c *PackageCache) file(sum string) string {
+	return filepath.Join(c.Path, sum+".json")
+}
+
+// syftFile returns the file of the Syft JSON fragment of an archive. The identifiers syft assigns depend on the
+// location of the archive, so the fragment of an archive that has moved cannot be reused.
+func (c *PackageCache) syftFile(sum string, location string) string {
+	return filepath.Join(c.Path, fmt.Sprintf("syft-%x.json", sha256.Sum256([]byte(sum+":"+location))))
+}
+
+// SBOMCache contributes the cache layer holding the PackageCache of the SBOM scanners and the license inventory.
+type SBOMCache struct{}
+
+func (SBOMCache) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	// the contents are written while scanning
+	layer.LayerTypes = libcnb.LayerTypes{Cache: true}
+	return layer, nil
+}
+
+func (SBOMCache) Name() string {
+	return "sbom-cache"
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testSBOMCache(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath   string
+		cachePath string
+		layers    libcnb.Layers
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+		cachePath = t.TempDir()
+		layers = libcnb.Layers{Path: t.TempDir()}
+
+		nested := filepath.Join(t.TempDir(), "nested.jar")
+		CreateZIP(t, nested, map[string]string{
+			"META-INF/maven/org.example/nested/pom.properties": "groupId=org.example\nartifactId=nested\nversion=1.0\n",
+		})
+		b, err := os.ReadFile(nested)
+		Expect(err).NotTo(HaveOccurred())
+
+		CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+			"META-INF/maven/com.example/app/pom.properties": "groupId=com.example\nartifactId=app\nversion=1.0.0\n",
+			"BOOT-INF/lib/nested-1.0.jar":                   string(b),
+		})
+		Expect(os.MkdirAll(filepath.Join(appPath, "lib"), 0755)).To(Succeed())
+		CreateZIP(t, filepath.Join(appPath, "lib", "plain-2.0.jar"), map[string]string{})
+	})
+
+	// scan returns the SBOM of appPath in every format, using cache if it is not nil
+	scan := func(cache *executable.PackageCache) map[libcnb.SBOMFormat][]byte {
+		scanner := executable.NewNativeSBOMScanner(layers, bard.NewLogger(os.Stdout))
+		scanner.Cache = cache
+
+		documents := map[libcnb.SBOMFormat][]byte{}
+		for _, format := range executable.SBOMFormats {
+			Expect(scanner.ScanLaunch(appPath, format)).To(Succeed())
+
+			b, err := os.ReadFile(layers.LaunchSBOMPath(format))
+			Expect(err).NotTo(HaveOccurred())
+			documents[format] = b
+		}
+		return documents
+	}
+
+	it("writes the same SBOM as a full scan", func() {
+		full := scan(nil)
+
+		cold := executable.NewPackageCache(cachePath)
+		Expect(scan(cold)).To(Equal(full))
+		Expect(cold.Misses).To(Equal(2))
+
+		warm := executable.NewPackageCache(cachePath)
+		Expect(scan(warm)).To(Equal(full))
+		Expect(warm.Misses).To(Equal(0))
+		Expect(warm.Hits).To(BeNumerically(">", 0))
+	})
+
+	it("only reads new and changed archives", func() {
+		scan(executable.NewPackageCache(cachePath))
+
+		CreateZIP(t, filepath.Join(appPath, "lib", "plain-2.0.jar"), map[string]string{"changed.txt": ""})
+		CreateZIP(t, filepath.Join(appPath, "lib", "added-3.0.jar"), map[string]string{})
+
+		cache := executable.NewPackageCache(cachePath)
+		Expect(scan(cache)).To(Equal(scan(nil)))
+		Expect(cache.Misses).To(Equal(2))
+	})
+
+	it("reads an archive that is renamed", func() {
+		scan(executable.NewPackageCache(cachePath))
+
+		Expect(os.Rename(filepath.Join(appPath, "lib", "plain-2.0.jar"), filepath.Join(appPath, "lib", "plain-2.1.jar"))).To(Succeed())
+
+		cache := executable.NewPackageCache(cachePath)
+		Expect(scan(cache)).To(Equal(scan(nil)))
+		Expect(cache.Misses).To(Equal(1))
+	})
+
+	it("reads archives cached by an earlier version", func() {
+		scan(executable.NewPackageCache(cachePath))
+
+		files, err := os.ReadDir(cachePath)
+		Expect(err).NotTo(HaveOccurred())
+		for _, f := range files {
+			Expect(os.WriteFile(filepath.Join(cachePath, f.Name()), []byte(`{"file":"plain-2.0.jar","packages":[]}`), 0644)).To(Succeed())
+		}
+
+		cache := executable.NewPackageCache(cachePath)
+		Expect(scan(cache)).To(Equal(scan(nil)))
+		Expect(cache.Misses).To(Equal(2))
+	})
+
+	it("prunes the packages of removed archives", func() {
+		scan(executable.NewPackageCache(cachePath))
+		files, err := os.ReadDir(cachePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(2))
+
+		Expect(os.Remove(filepath.Join(appPath, "lib", "plain-2.0.jar"))).To(Succeed())
+
+		cache := executable.NewPackageCache(cachePath)
+		scan(cache)
+		Expect(cache.Prune()).To(Succeed())
+
+		files, err = os.ReadDir(cachePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+
+	it("contributes a cache layer", func() {
+		layer, err := layers.Layer(executable.SBOMCache{}.Name())
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = executable.SBOMCache{}.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(layer.LayerTypes).To(Equal(libcnb.LayerTypes{Cache: true}))
+	})
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/santhosh-tekuri/jsonschema/v5/httploader"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+// sbomSchemas are the JSON schemas of the SBOM formats, the published schemas except for the Syft JSON that libpak
+// writes for dependencies, for which syft publishes none.
+var sbomSchemas = map[libcnb.SBOMFormat]string{
+	libcnb.CycloneDXJSON: "https://raw.githubusercontent.com/CycloneDX/specification/1.4/schema/bom-1.4.schema.json",
+	libcnb.SPDXJSON:      "https://raw.githubusercontent.com/spdx/spdx-spec/v2.3/schemas/spdx-schema.json",
+	libcnb.SyftJSON:      filepath.Join("testdata", "schemas", "syft-dependency.schema.json"),
+}
+
+var compiledSBOMSchemas = map[libcnb.SBOMFormat]*jsonschema.Schema{}
+
+// sbomSchema returns the compiled schema of format, skipping the test if a published schema cannot be downloaded.
+func sbomSchema(t *testing.T, format libcnb.SBOMFormat) *jsonschema.Schema {
+	t.Helper()
+
+	if s, ok := compiledSBOMSchemas[format]; ok {
+		return s
+	}
+
+	url := sbomSchemas[format]
+	if strings.HasPrefix(url, "https://") {
+		httploader.Client = &http.Client{Timeout: 30 * time.Second}
+		resp, err := httploader.Client.Get(url)
+		if err != nil {
+			t.Skipf("unable to download %s: %s", url, err)
+		}
+		resp.Body.Close()
+	}
+
+	s, err := jsonschema.Compile(url)
+	if err != nil {
+		t.Fatalf("unable to compile %s: %s", url, err)
+	}
+	compiledSBOMSchemas[format] = s
+	return s
+}
+
+func testSBOM(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appPath string
+		layers  libcnb.Layers
+		scanner executable.NativeSBOMScanner
+	)
+
+	it.Before(func() {
+		appPath = t.TempDir()
+		layers = libcnb.Layers{Path: t.TempDir()}
+		scanner = executable.NewNativeSBOMScanner(layers, bard.NewLogger(os.Stdout))
+
+		CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+			"META-INF/maven/com.example/app/pom.properties": "groupId=com.example\nartifactId=app\nversion=1.0.0\n",
+		})
+	})
+
+	read := func(file string) map[string]interface{} {
+		b, err := os.ReadFile(file)
+		Expect(err).NotTo(HaveOccurred())
+
+		doc := map[string]interface{}{}
+		Expect(json.Unmarshal(b, &doc)).To(Succeed())
+		return doc
+	}
+
+	it("writes a CycloneDX launch SBOM", func() {
+		Expect(scanner.ScanLaunch(appPath, libcnb.CycloneDXJSON)).To(Succeed())
+
+		doc := read(layers.LaunchSBOMPath(libcnb.CycloneDXJSON))
+		Expect(doc).To(HaveKeyWithValue("bomFormat", "CycloneDX"))
+		Expect(doc).NotTo(HaveKey("serialNumber"))
+		Expect(doc["components"]).To(ConsistOf(SatisfyAll(
+			HaveKeyWithValue("type", "library"),
+			HaveKeyWithValue("group", "com.example"),
+			HaveKeyWithValue("name", "app"),
+			HaveKeyWithValue("version", "1.0.0"),
+			HaveKeyWithValue("purl", "pkg:maven/com.example/app@1.0.0"),
+		)))
+	})
+
+	it("writes a Syft JSON build SBOM", func() {
+		Expect(scanner.ScanBuild(appPath, libcnb.SyftJSON)).To(Succeed())
+
+		doc := read(layers.BuildSBOMPath(libcnb.SyftJSON))
+		Expect(doc["Source"]).To(HaveKeyWithValue("Target", appPath))
+		Expect(doc["Artifacts"]).To(ConsistOf(SatisfyAll(
+			HaveKeyWithValue("Name", "app"),
+			HaveKeyWithValue("Version", "1.0.0"),
+			HaveKeyWithValue("PURL", "pkg:maven/com.example/app@1.0.0"),
+			HaveKeyWithValue("Locations", ConsistOf(HaveKeyWithValue("Path", "app.jar"))),
+		)))
+	})
+
+	it("writes a layer SBOM", func() {
+		layer, err := layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(scanner.ScanLayer(layer, appPath, libcnb.CycloneDXJSON)).To(Succeed())
+		Expect(layer.SBOMPath(libcnb.CycloneDXJSON)).To(BeARegularFile())
+	})
+
+	it("includes checksums and Class-Path entries outside of the application", func() {
+		external := t.TempDir()
+		CreateZIP(t, filepath.Join(external, "shared-2.0.jar"), map[string]string{})
+		scanner.ClassPath = []string{filepath.Join(appPath, "app.jar"), filepath.Join(external, "shared-2.0.jar")}
+
+		Expect(scanner.ScanLaunch(appPath, libcnb.CycloneDXJSON)).To(Succeed())
+
+		doc := read(layers.LaunchSBOMPath(libcnb.CycloneDXJSON))
+		Expect(doc["components"]).To(ConsistOf(
+			HaveKeyWithValue("purl", "pkg:maven/com.example/app@1.0.0"),
+			HaveKeyWithValue("purl", "pkg:maven/shared@2.0"),
+		))
+		Expect(doc["components"]).To(HaveEach(HaveKeyWithValue("hashes", ConsistOf(SatisfyAll(
+			HaveKeyWithValue("alg", "SHA-256"),
+			HaveKeyWithValue("content", HaveLen(64)),
+		)))))
+	})
+
+	it("is reproducible", func() {
+		Expect(scanner.ScanLaunch(appPath, libcnb.CycloneDXJSON)).To(Succeed())
+		first, err := os.ReadFile(layers.LaunchSBOMPath(libcnb.CycloneDXJSON))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(scanner.ScanLaunch(appPath, libcnb.CycloneDXJSON)).To(Succeed())
+		Expect(os.ReadFile(layers.LaunchSBOMPath(libcnb.CycloneDXJSON))).To(Equal(first))
+	})
+
+	it("writes an SPDX launch SBOM", func() {
+		Expect(scanner.ScanLaunch(appPath, libcnb.SPDXJSON)).To(Succeed())
+
+		doc := read(layers.LaunchSBOMPath(libcnb.SPDXJSON))
+		Expect(doc).To(HaveKeyWithValue("spdxVersion", "SPDX-2.3"))
+		Expect(doc["packages"]).To(ConsistOf(SatisfyAll(
+			HaveKeyWithValue("name", "app"),
+			HaveKeyWithValue("versionInfo", "1.0.0"),
+			HaveKeyWithValue("externalRefs", ConsistOf(HaveKeyWithValue("referenceLocator", "pkg:maven/com.example/app@1.0.0"))),
+		)))
+		Expect(doc["relationships"]).To(ConsistOf(HaveKeyWithValue("relationshipType", "DESCRIBES")))
+	})
+
+	context("schemas", func() {
+		it.Before(func() {
+			nested := filepath.Join(t.TempDir(), "nested.jar")
+			CreateZIP(t, nested, map[string]string{
+				"META-INF/MANIFEST.MF": "Bundle-SymbolicName: org.example.bundle\nBundle-Version: 2.1.0\nBundle-License: Apache-2.0, https://example.com/license\n",
+			})
+			b, err := os.ReadFile(nested)
+			Expect(err).NotTo(HaveOccurred())
+
+			CreateZIP(t, filepath.Join(appPath, "lib.jar"), map[string]string{
+				"BOOT-INF/lib/bundle.jar": string(b),
+				"BOOT-INF/lib/plain.jar":  string(b),
+			})
+		})
+
+		var names []string
+		for name := range executable.SBOMFormats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			format := executable.SBOMFormats[name]
+
+			it(fmt.Sprintf("writes %s documents that are valid", name), func() {
+				schema := sbomSchema(t, format)
+
+				Expect(scanner.ScanLaunch(appPath, format)).To(Succeed())
+				Expect(schema.Validate(read(layers.LaunchSBOMPath(format)))).To(Succeed())
+			})
+
+			it(fmt.Sprintf("writes %s documents that are valid without packages", name), func() {
+				schema := sbomSchema(t, format)
+				Expect(os.RemoveAll(appPath)).To(Succeed())
+				Expect(os.MkdirAll(appPath, 0755)).To(Succeed())
+
+				Expect(scanner.ScanLaunch(appPath, format)).To(Succeed())
+				Expect(schema.Validate(read(layers.LaunchSBOMPath(format)))).To(Succeed())
+			})
+		}
+
+		it("declares every format in buildpack.toml", func() {
+			var buildpack struct {
+				Buildpack libcnb.BuildpackInfo `toml:"buildpack"`
+			}
+			_, err := toml.DecodeFile(filepath.Join("..", "buildpack.toml"), &buildpack)
+			Expect(err).NotTo(HaveOccurred())
+
+			var mediaTypes []string
+			for _, format := range executable.SBOMFormats {
+				mediaTypes = append(mediaTypes, format.MediaType())
+			}
+			Expect(buildpack.Buildpack.SBOMFormats).To(ConsistOf(mediaTypes))
+		})
+	})
+
+	it("returns an error for an unsupported format", func() {
+		Expect(scanner.ScanLaunch(appPath, libcnb.UnknownFormat)).To(MatchError(ContainSubstring("unsupported SBOM format")))
+	})
+}
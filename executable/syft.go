@@ -0,0 +1,461 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/paketo-buildpacks/libpak/sbom"
+)
+
+// SyftSBOMScanner is an sbom.SBOMScanner that runs the syft CLI like libpak's SyftCLISBOMScanner. Unlike it, both the
+// CycloneDX and the SPDX documents it writes are reproducible.
+//
+// If it has a Cache, the archives in the scanned directory are scanned separately from the rest of it and the parts of
+// the Syft JSON document that describe each archive are kept in the cache. Only new and changed archives are scanned
+// again, and the SBOM is converted from the merged Syft JSON document, so it is the same whether or not the archives
+// were scanned again. It is not always the same as the SBOM of a single scan of the directory: relationships between
+// artifacts of different archives are lost and the artifacts may be in a different order.
+type SyftSBOMScanner struct {
+	Executor effect.Executor
+	Layers   libcnb.Layers
+	Logger   bard.Logger
+
+	// Cache holds the Syft JSON fragments of the archives scanned by earlier builds, nil to scan every archive.
+	Cache *PackageCache
+}
+
+func NewSyftSBOMScanner(layers libcnb.Layers, executor effect.Executor, logger bard.Logger) SyftSBOMScanner {
+	return SyftSBOMScanner{Executor: executor, Layers: layers, Logger: logger}
+}
+
+// ScanLayer writes the SBOM of scanDir to the layer SBOM files in the given formats.
+func (s SyftSBOMScanner) ScanLayer(layer libcnb.Layer, scanDir string, formats ...libcnb.SBOMFormat) error {
+	return s.scan(layer.SBOMPath, scanDir, formats...)
+}
+
+// ScanBuild writes the SBOM of scanDir to the build SBOM files in the given formats.
+func (s SyftSBOMScanner) ScanBuild(scanDir string, formats ...libcnb.SBOMFormat) error {
+	return s.scan(s.Layers.BuildSBOMPath, scanDir, formats...)
+}
+
+// ScanLaunch writes the SBOM of scanDir to the launch SBOM files in the given formats.
+func (s SyftSBOMScanner) ScanLaunch(scanDir string, formats ...libcnb.SBOMFormat) error {
+	return s.scan(s.Layers.LaunchSBOMPath, scanDir, formats...)
+}
+
+func (s SyftSBOMScanner) scan(sbomPath func(libcnb.SBOMFormat) string, scanDir string, formats ...libcnb.SBOMFormat) error {
+	var outputs []string
+	for _, format := range formats {
+		outputs = append(outputs, "-o", fmt.Sprintf("%s=%s", sbom.SBOMFormatToSyftOutputFormat(format), sbomPath(format)))
+	}
+
+	if s.Cache == nil {
+		if err := s.syft(append(append([]string{"scan", "-q"}, outputs...), fmt.Sprintf("dir:%s", scanDir))...); err != nil {
+			return err
+		}
+	} else {
+		tmp, err := os.MkdirTemp("", "syft")
+		if err != nil {
+			return fmt.Errorf("unable to create temporary directory\n%w", err)
+		}
+		defer os.RemoveAll(tmp)
+
+		file, err := s.scanCached(tmp, scanDir)
+		if err != nil {
+			return err
+		}
+		if err := s.syft(append([]string{"convert", file}, outputs...)...); err != nil {
+			return err
+		}
+	}
+
+	for _, format := range formats {
+		if err := makeReproducible(sbomPath(format), format); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanCached writes the Syft JSON document of scanDir into tmp and returns its file. The directory without its archives
+// is scanned first, then the archives that have no fragment in the cache are scanned together in a copy of the
+// directory that holds only them, and the fragments of all archives are merged into the document of the directory in
+// the order of their paths.
+func (s SyftSBOMScanner) scanCached(tmp string, scanDir string) (string, error) {
+	directory := filepath.Join(tmp, "directory.json")
+	args := []string{"scan", "-q"}
+	for _, ext := range []string{".jar", ".war"} {
+		args = append(args, "--exclude", fmt.Sprintf("**/*%s", ext))
+	}
+	if err := s.syft(append(args, "-o", fmt.Sprintf("json=%s", directory), fmt.Sprintf("dir:%s", scanDir))...); err != nil {
+		return "", err
+	}
+
+	archives, err := findArchives(scanDir)
+	if err != nil {
+		return "", err
+	}
+
+	fragments := make([]syftFragment, len(archives))
+	sums := make([]string, len(archives))
+	var missed []int
+	for i, a := range archives {
+		if sums[i], err = checksum(os.DirFS(scanDir), a); err != nil {
+			return "", fmt.Errorf("unable to checksum %s\n%w", a, err)
+		}
+
+		if f, ok := s.Cache.getSyft(sums[i], a); ok {
+			fragments[i] = f
+		} else {
+			missed = append(missed, i)
+		}
+	}
+	s.Logger.Bodyf("Syft scanned %d archives, reused the SBOMs of %d unchanged archives", len(missed), len(archives)-len(missed))
+
+	if len(missed) > 0 {
+		dir := filepath.Join(tmp, "archives")
+		for _, i := range missed {
+			if err := linkFile(filepath.Join(scanDir, filepath.FromSlash(archives[i])), filepath.Join(dir, filepath.FromSlash(archives[i]))); err != nil {
+				return "", err
+			}
+		}
+
+		file := filepath.Join(tmp, "archives.json")
+		if err := s.syft("scan", "-q", "-o", fmt.Sprintf("json=%s", file), fmt.Sprintf("dir:%s", dir)); err != nil {
+			return "", err
+		}
+
+		split, err := splitSyftJSON(file)
+		if err != nil {
+			return "", err
+		}
+		for _, i := range missed {
+			fragments[i] = split[archives[i]]
+			if err := s.Cache.putSyft(sums[i], archives[i], fragments[i]); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	file := filepath.Join(tmp, "sbom.json")
+	if err := mergeSyftJSON(directory, fragments, file); err != nil {
+		return "", err
+	}
+
+	return file, nil
+}
+
+func (s SyftSBOMScanner) syft(args ...string) error {
+	if err := s.Executor.Execute(effect.Execution{
+		Command: "syft",
+		Args:    args,
+		Stdout:  s.Logger.TerminalErrorWriter(),
+		Stderr:  s.Logger.TerminalErrorWriter(),
+	}); err != nil {
+		return fmt.Errorf("unable to run `syft %s`\n%w", args, err)
+	}
+
+	return nil
+}
+
+// syftDocument is a Syft JSON document with the sections that describe packages decoded.
+type syftDocument struct {
+	Artifacts []struct {
+		ID        string `json:"id"`
+		Locations []struct {
+			Path string `json:"path"`
+		} `json:"locations"`
+	} `json:"artifacts"`
+	Relationships []struct {
+		Parent string `json:"parent"`
+		Child  string `json:"child"`
+	} `json:"artifactRelationships"`
+	Files []struct {
+		ID       string `json:"id"`
+		Location struct {
+			Path string `json:"path"`
+		} `json:"location"`
+	} `json:"files"`
+}
+
+// splitSyftJSON returns the fragments of the Syft JSON document in file, keyed by the path of the archive they describe
+// relative to the scanned directory. Artifacts and files belong to the archive at their location, and relationships to
+// the archive of their parent or, if the parent is not an artifact or a file, their child.
+func splitSyftJSON(file string) (map[string]syftFragment, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	var raw struct {
+		Artifacts     []json.RawMessage `json:"artifacts"`
+		Relationships []json.RawMessage `json:"artifactRelationships"`
+		Files         []json.RawMessage `json:"files"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("unable to decode %s\n%w", file, err)
+	}
+	var doc syftDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("unable to decode %s\n%w", file, err)
+	}
+
+	fragments := map[string]syftFragment{}
+	owners := map[string]string{}
+
+	for i, a := range doc.Artifacts {
+		if len(a.Locations) == 0 {
+			continue
+		}
+		owner := archiveOf(a.Locations[0].Path)
+		owners[a.ID] = owner
+
+		f := fragments[owner]
+		f.Artifacts = append(f.Artifacts, raw.Artifacts[i])
+		fragments[owner] = f
+	}
+
+	for i, file := range doc.Files {
+		owner := archiveOf(file.Location.Path)
+		owners[file.ID] = owner
+
+		f := fragments[owner]
+		f.Files = append(f.Files, raw.Files[i])
+		fragments[owner] = f
+	}
+
+	for i, r := range doc.Relationships {
+		owner, ok := owners[r.Parent]
+		if !ok {
+			if owner, ok = owners[r.Child]; !ok {
+				continue
+			}
+		}
+
+		f := fragments[owner]
+		f.Relationships = append(f.Relationships, raw.Relationships[i])
+		fragments[owner] = f
+	}
+
+	return fragments, nil
+}
+
+// mergeSyftJSON writes the Syft JSON document in directory with the artifacts, relationships and files of fragments
+// added to file. Entries that are already in the document are not added again.
+func mergeSyftJSON(directory string, fragments []syftFragment, file string) error {
+	b, err := os.ReadFile(directory)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", directory, err)
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("unable to decode %s\n%w", directory, err)
+	}
+
+	sections := map[string]func(syftFragment) []json.RawMessage{
+		"artifacts":             func(f syftFragment) []json.RawMessage { return f.Artifacts },
+		"artifactRelationships": func(f syftFragment) []json.RawMessage { return f.Relationships },
+		"files":                 func(f syftFragment) []json.RawMessage { return f.Files },
+	}
+	for key, section := range sections {
+		var entries []json.RawMessage
+		if raw, ok := doc[key]; ok {
+			if err := json.Unmarshal(raw, &entries); err != nil {
+				return fmt.Errorf("unable to decode %s of %s\n%w", key, directory, err)
+			}
+		}
+		for _, f := range fragments {
+			entries = append(entries, section(f)...)
+		}
+
+		if doc[key], err = json.Marshal(dedupeRaw(entries)); err != nil {
+			return fmt.Errorf("unable to encode %s\n%w", key, err)
+		}
+	}
+
+	if b, err = json.Marshal(doc); err != nil {
+		return fmt.Errorf("unable to encode %s\n%w", file, err)
+	}
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	return nil
+}
+
+// dedupeRaw returns entries without those that are the same as an earlier entry, never nil.
+func dedupeRaw(entries []json.RawMessage) []json.RawMessage {
+	seen := map[string]bool{}
+	deduped := []json.RawMessage{}
+	for _, e := range entries {
+		var b bytes.Buffer
+		if err := json.Compact(&b, e); err != nil {
+			b.Reset()
+			b.Write(e)
+		}
+		if seen[b.String()] {
+			continue
+		}
+		seen[b.String()] = true
+		deduped = append(deduped, e)
+	}
+	return deduped
+}
+
+// archiveOf returns the path of the archive relative to the scanned directory of a syft location, which is the path of
+// the archive for the entries nested within it, optionally followed by a colon and the path within the archive.
+func archiveOf(location string) string {
+	location = strings.TrimPrefix(location, "/")
+	if i := strings.Index(location, ":"); i >= 0 {
+		location = location[:i]
+	}
+	return location
+}
+
+// findArchives returns the paths of the JAR and WAR files in dir relative to it, in lexical order.
+func findArchives(dir string) ([]string, error) {
+	var archives []string
+	if err := fs.WalkDir(os.DirFS(dir), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && isArchive(name) {
+			archives = append(archives, name)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to find archives in %s\n%w", dir, err)
+	}
+
+	return archives, nil
+}
+
+// linkFile hard links source to destination, creating the directories of destination, or copies it if it cannot be
+// linked.
+func linkFile(source string, destination string) error {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return fmt.Errorf("unable to create %s\n%w", filepath.Dir(destination), err)
+	}
+	if err := os.Link(source, destination); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", source, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("unable to create %s\n%w", destination, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("unable to copy %s to %s\n%w", source, destination, err)
+	}
+
+	return nil
+}
+
+// makeReproducible rewrites the SBOM file in format without the parts of syft documents that change on every scan.
+func makeReproducible(file string, format libcnb.SBOMFormat) error {
+	var reproducible func(map[string]interface{}) error
+	switch format {
+	case libcnb.CycloneDXJSON:
+		reproducible = reproducibleCycloneDX
+	case libcnb.SPDXJSON:
+		reproducible = reproducibleSPDX
+	default:
+		return nil
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	doc, err := decodeDocument(b)
+	if err != nil {
+		return fmt.Errorf("unable to decode %s\n%w", file, err)
+	}
+	if err := reproducible(doc); err != nil {
+		return fmt.Errorf("unable to make %s reproducible\n%w", file, err)
+	}
+
+	if b, err = json.Marshal(doc); err != nil {
+		return fmt.Errorf("unable to encode %s\n%w", file, err)
+	}
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	return nil
+}
+
+// decodeDocument decodes the JSON document b, keeping numbers as they are written.
+func decodeDocument(b []byte) (map[string]interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	doc := map[string]interface{}{}
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// reproducibleCycloneDX removes the random serial number and the timestamp of a CycloneDX document, as libpak does.
+func reproducibleCycloneDX(doc map[string]interface{}) error {
+	delete(doc, "serialNumber")
+	if metadata, ok := doc["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "timestamp")
+	}
+	return nil
+}
+
+// reproducibleSPDX fixes the creation time of an SPDX document and replaces its namespace, which syft makes unique
+// with a random UUID, with one derived from the rest of the document, like NewSPDX. Both are required by SPDX.
+func reproducibleSPDX(doc map[string]interface{}) error {
+	if info, ok := doc["creationInfo"].(map[string]interface{}); ok {
+		info["created"] = spdxCreated
+	}
+
+	delete(doc, "documentNamespace")
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	doc["documentNamespace"] = fmt.Sprintf("%s%x", spdxNamespace, sha256.Sum256(b))
+
+	return nil
+}
@@ -0,0 +1,405 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+// executorFunc is an effect.Executor that calls itself.
+type executorFunc func(execution effect.Execution) error
+
+func (e executorFunc) Execute(execution effect.Execution) error {
+	return e(execution)
+}
+
+// syftSPDX returns an SPDX document in the form syft writes, with a namespace and creation time that change on every
+// scan.
+func syftSPDX(uuid string, created string) string {
+	return fmt.Sprintf(`{
+ "spdxVersion": "SPDX-2.3",
+ "dataLicense": "CC0-1.0",
+ "SPDXID": "SPDXRef-DOCUMENT",
+ "name": "/workspace",
+ "documentNamespace": "https://anchore.com/syft/dir/workspace-%s",
+ "creationInfo": {
+  "licenseListVersion": "3.22",
+  "creators": ["Organization: Anchore, Inc", "Tool: syft-1.0.0"],
+  "created": "%s"
+ },
+ "packages": [
+  {
+   "name": "log4j-core",
+   "SPDXID": "SPDXRef-Package-java-archive-log4j-core-8c2a1e3f0b7d5c9a",
+   "versionInfo": "2.17.1",
+   "supplier": "NOASSERTION",
+   "downloadLocation": "NOASSERTION",
+   "filesAnalyzed": false,
+   "sourceInfo": "acquired package info from installed java archive: /lib/log4j-core-2.17.1.jar",
+   "licenseConcluded": "NOASSERTION",
+   "licenseDeclared": "NOASSERTION",
+   "copyrightText": "NOASSERTION",
+   "externalRefs": [
+    {
+     "referenceCategory": "PACKAGE-MANAGER",
+     "referenceType": "purl",
+     "referenceLocator": "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.1"
+    }
+   ]
+  }
+ ],
+ "relationships": [
+  {
+   "spdxElementId": "SPDXRef-DOCUMENT",
+   "relatedSpdxElement": "SPDXRef-Package-java-archive-log4j-core-8c2a1e3f0b7d5c9a",
+   "relationshipType": "DESCRIBES"
+  }
+ ]
+}`, uuid, created)
+}
+
+func testSyft(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layers     libcnb.Layers
+		executions []effect.Execution
+		documents  map[string]string
+	)
+
+	it.Before(func() {
+		layers = libcnb.Layers{Path: t.TempDir()}
+		executions = nil
+		documents = map[string]string{
+			"cyclonedx-json": `{"bomFormat": "CycloneDX", "specVersion": "1.4", "serialNumber": "urn:uuid:0b0e6b8c", "version": 1, "metadata": {"timestamp": "2024-01-01T10:00:00Z"}}`,
+			"spdx-json":      syftSPDX("0b0e6b8c-4f8b-4b4e-9f55-8f0e4f3c2a11", "2024-01-01T10:00:00Z"),
+			"json":           `{"artifacts": []}`,
+		}
+	})
+
+	scanner := func() executable.SyftSBOMScanner {
+		return executable.NewSyftSBOMScanner(layers, executorFunc(func(execution effect.Execution) error {
+			executions = append(executions, execution)
+			for i := 0; i+1 < len(execution.Args); i++ {
+				if execution.Args[i] != "-o" {
+					continue
+				}
+				format, file, _ := strings.Cut(execution.Args[i+1], "=")
+				if err := os.WriteFile(file, []byte(documents[format]), 0644); err != nil {
+					return err
+				}
+			}
+			return nil
+		}), bard.NewLogger(io.Discard))
+	}
+
+	read := func(format libcnb.SBOMFormat) map[string]interface{} {
+		b, err := os.ReadFile(layers.LaunchSBOMPath(format))
+		Expect(err).NotTo(HaveOccurred())
+
+		doc := map[string]interface{}{}
+		Expect(json.Unmarshal(b, &doc)).To(Succeed())
+		return doc
+	}
+
+	it("runs syft with an output for each format", func() {
+		Expect(scanner().ScanLaunch("/workspace", libcnb.SyftJSON, libcnb.SPDXJSON)).To(Succeed())
+
+		Expect(executions).To(HaveLen(1))
+		Expect(executions[0].Command).To(Equal("syft"))
+		Expect(executions[0].Args).To(Equal([]string{"scan", "-q",
+			"-o", "json=" + layers.LaunchSBOMPath(libcnb.SyftJSON),
+			"-o", "spdx-json=" + layers.LaunchSBOMPath(libcnb.SPDXJSON),
+			"dir:/workspace",
+		}))
+	})
+
+	it("removes the serial number and timestamp of CycloneDX documents", func() {
+		Expect(scanner().ScanLaunch("/workspace", libcnb.CycloneDXJSON)).To(Succeed())
+
+		doc := read(libcnb.CycloneDXJSON)
+		Expect(doc).NotTo(HaveKey("serialNumber"))
+		Expect(doc).To(HaveKeyWithValue("metadata", BeEmpty()))
+	})
+
+	it("writes reproducible SPDX documents", func() {
+		Expect(scanner().ScanLaunch("/workspace", libcnb.SPDXJSON)).To(Succeed())
+		first, err := os.ReadFile(layers.LaunchSBOMPath(libcnb.SPDXJSON))
+		Expect(err).NotTo(HaveOccurred())
+
+		documents["spdx-json"] = syftSPDX("7d7a3b1e-2c55-4a2b-8d0e-2b1f6a9c4e77", "2024-06-30T23:59:59Z")
+		Expect(scanner().ScanLaunch("/workspace", libcnb.SPDXJSON)).To(Succeed())
+		second, err := os.ReadFile(layers.LaunchSBOMPath(libcnb.SPDXJSON))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second).To(Equal(first))
+
+		doc := read(libcnb.SPDXJSON)
+		Expect(doc).To(HaveKeyWithValue("creationInfo", HaveKeyWithValue("created", "1980-01-01T00:00:01Z")))
+		Expect(doc).To(HaveKeyWithValue("documentNamespace", HavePrefix("https://paketo.io/spdx/executable-jar/")))
+	})
+
+	it("writes SPDX documents that are valid", func() {
+		schema := sbomSchema(t, libcnb.SPDXJSON)
+
+		Expect(scanner().ScanLaunch("/workspace", libcnb.SPDXJSON)).To(Succeed())
+		Expect(schema.Validate(read(libcnb.SPDXJSON))).To(Succeed())
+	})
+
+	context("with a cache", func() {
+		var (
+			appPath string
+			cache   *executable.PackageCache
+			scanned []string
+		)
+
+		it.Before(func() {
+			appPath = t.TempDir()
+			cache = executable.NewPackageCache(t.TempDir())
+			scanned = nil
+
+			Expect(os.MkdirAll(filepath.Join(appPath, "lib"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "app.jar"), []byte("app"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "lib", "a.jar"), []byte("a"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "lib", "b.jar"), []byte("b"), 0644)).To(Succeed())
+		})
+
+		// syft fakes the syft CLI, writing an artifact, a nested artifact and a file for each archive it scans, and an
+		// artifact for the directory when archives are excluded. Converting copies the Syft JSON document.
+		syft := executorFunc(func(execution effect.Execution) error {
+			executions = append(executions, execution)
+
+			var outputs []string
+			for i := 0; i+1 < len(execution.Args); i++ {
+				if execution.Args[i] == "-o" {
+					_, file, _ := strings.Cut(execution.Args[i+1], "=")
+					outputs = append(outputs, file)
+				}
+			}
+
+			var b []byte
+			switch execution.Args[0] {
+			case "convert":
+				var err error
+				if b, err = os.ReadFile(execution.Args[1]); err != nil {
+					return err
+				}
+			case "scan":
+				dir := strings.TrimPrefix(execution.Args[len(execution.Args)-1], "dir:")
+				doc := map[string]interface{}{
+					"artifacts":             []interface{}{},
+					"artifactRelationships": []interface{}{},
+					"files":                 []interface{}{},
+					"source":                map[string]interface{}{"type": "directory", "target": dir},
+				}
+
+				if execution.Args[2] == "--exclude" {
+					doc["artifacts"] = []interface{}{
+						map[string]interface{}{"id": "directory", "locations": []interface{}{map[string]interface{}{"path": "/pom.xml"}}},
+					}
+				} else {
+					archives, err := filepath.Glob(filepath.Join(dir, "*.jar"))
+					Expect(err).NotTo(HaveOccurred())
+					more, err := filepath.Glob(filepath.Join(dir, "lib", "*.jar"))
+					Expect(err).NotTo(HaveOccurred())
+
+					for _, a := range append(archives, more...) {
+						content, err := os.ReadFile(a)
+						Expect(err).NotTo(HaveOccurred())
+
+						rel, err := filepath.Rel(dir, a)
+						Expect(err).NotTo(HaveOccurred())
+						rel = filepath.ToSlash(rel)
+						scanned = append(scanned, rel)
+
+						id := fmt.Sprintf("%s-%s", rel, content)
+						doc["artifacts"] = append(doc["artifacts"].([]interface{}),
+							map[string]interface{}{"id": id, "locations": []interface{}{map[string]interface{}{"path": "/" + rel}}},
+							map[string]interface{}{"id": id + "-nested", "locations": []interface{}{map[string]interface{}{"path": "/" + rel + ":BOOT-INF/lib/nested.jar"}}},
+						)
+						doc["artifactRelationships"] = append(doc["artifactRelationships"].([]interface{}),
+							map[string]interface{}{"parent": id, "child": "file-" + rel, "type": "contains"},
+						)
+						doc["files"] = append(doc["files"].([]interface{}),
+							map[string]interface{}{"id": "file-" + rel, "location": map[string]interface{}{"path": "/" + rel}},
+						)
+					}
+				}
+
+				var err error
+				if b, err = json.Marshal(doc); err != nil {
+					return err
+				}
+			}
+
+			for _, file := range outputs {
+				if err := os.WriteFile(file, b, 0644); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		// scan returns the Syft JSON SBOM of appPath
+		scan := func() []byte {
+			s := executable.NewSyftSBOMScanner(layers, syft, bard.NewLogger(io.Discard))
+			s.Cache = cache
+			Expect(s.ScanLaunch(appPath, libcnb.SyftJSON)).To(Succeed())
+
+			b, err := os.ReadFile(layers.LaunchSBOMPath(libcnb.SyftJSON))
+			Expect(err).NotTo(HaveOccurred())
+			return b
+		}
+
+		it("merges the SBOMs of the archives into the SBOM of the directory", func() {
+			scan()
+			Expect(scanned).To(Equal([]string{"app.jar", "lib/a.jar", "lib/b.jar"}))
+
+			doc := read(libcnb.SyftJSON)
+			Expect(doc).To(HaveKeyWithValue("source", HaveKeyWithValue("target", appPath)))
+			Expect(doc["artifacts"]).To(HaveLen(7))
+			Expect(doc["artifacts"]).To(ContainElement(HaveKeyWithValue("id", "directory")))
+			Expect(doc["artifacts"]).To(ContainElement(HaveKeyWithValue("id", "lib/a.jar-a-nested")))
+			Expect(doc["artifactRelationships"]).To(HaveLen(3))
+			Expect(doc["files"]).To(HaveLen(3))
+		})
+
+		it("writes the same SBOM whether or not archives are cached", func() {
+			cold := scan()
+
+			scanned = nil
+			cache = executable.NewPackageCache(cache.Path)
+			warm := scan()
+
+			Expect(scanned).To(BeEmpty())
+			Expect(warm).To(Equal(cold))
+		})
+
+		it("only scans new and changed archives", func() {
+			scan()
+
+			Expect(os.WriteFile(filepath.Join(appPath, "lib", "a.jar"), []byte("a2"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "lib", "c.jar"), []byte("c"), 0644)).To(Succeed())
+
+			scanned = nil
+			cache = executable.NewPackageCache(cache.Path)
+			scan()
+
+			Expect(scanned).To(Equal([]string{"lib/a.jar", "lib/c.jar"}))
+			doc := read(libcnb.SyftJSON)
+			Expect(doc["artifacts"]).To(ContainElement(HaveKeyWithValue("id", "lib/a.jar-a2")))
+			Expect(doc["artifacts"]).NotTo(ContainElement(HaveKeyWithValue("id", "lib/a.jar-a")))
+		})
+
+		it("scans an archive that is moved", func() {
+			scan()
+
+			Expect(os.Rename(filepath.Join(appPath, "lib", "b.jar"), filepath.Join(appPath, "b.jar"))).To(Succeed())
+
+			scanned = nil
+			cache = executable.NewPackageCache(cache.Path)
+			scan()
+
+			Expect(scanned).To(Equal([]string{"b.jar"}))
+		})
+
+		it("prunes the SBOMs of removed archives", func() {
+			scan()
+			Expect(os.Remove(filepath.Join(appPath, "lib", "b.jar"))).To(Succeed())
+
+			cache = executable.NewPackageCache(cache.Path)
+			scan()
+			Expect(cache.Prune()).To(Succeed())
+
+			files, err := os.ReadDir(cache.Path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(2))
+		})
+	})
+
+	context("with syft", func() {
+		it.Before(func() {
+			if _, err := exec.LookPath("syft"); err != nil {
+				t.Skip("syft is not available")
+			}
+		})
+
+		// the cached scan is only enabled by default once this passes
+		it("writes the same SBOM with a cache as a single scan", func() {
+			appPath := t.TempDir()
+			Expect(os.MkdirAll(filepath.Join(appPath, "lib"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appPath, "lib", "log4j-core-2.14.1.jar"), []byte(log4jCore(t, "2.14.1")), 0644)).To(Succeed())
+			CreateZIP(t, filepath.Join(appPath, "app.jar"), map[string]string{
+				"META-INF/MANIFEST.MF":                          "Main-Class: com.example.Main\nClass-Path: lib/log4j-core-2.14.1.jar\n",
+				"BOOT-INF/lib/log4j-core-2.17.1.jar":            log4jCore(t, "2.17.1"),
+				"META-INF/maven/com.example/app/pom.properties": "groupId=com.example\nartifactId=app\nversion=1.0\n",
+			})
+			Expect(os.WriteFile(filepath.Join(appPath, "pom.xml"), []byte(`<project>
+  <modelVersion>4.0.0</modelVersion>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0</version>
+</project>
+`), 0644)).To(Succeed())
+
+			formats := []libcnb.SBOMFormat{libcnb.SyftJSON, libcnb.CycloneDXJSON, libcnb.SPDXJSON}
+
+			single := libcnb.Layers{Path: t.TempDir()}
+			Expect(executable.NewSyftSBOMScanner(single, effect.CommandExecutor{}, bard.NewLogger(io.Discard)).
+				ScanLaunch(appPath, formats...)).To(Succeed())
+
+			cache := executable.NewPackageCache(t.TempDir())
+			for i := 0; i < 2; i++ {
+				cached := libcnb.Layers{Path: t.TempDir()}
+				s := executable.NewSyftSBOMScanner(cached, effect.CommandExecutor{}, bard.NewLogger(io.Discard))
+				s.Cache = cache
+				Expect(s.ScanLaunch(appPath, formats...)).To(Succeed())
+
+				for _, f := range formats {
+					expected, err := os.ReadFile(single.LaunchSBOMPath(f))
+					Expect(err).NotTo(HaveOccurred())
+					actual, err := os.ReadFile(cached.LaunchSBOMPath(f))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(actual)).To(Equal(string(expected)), "%s, scan %d", f, i+1)
+				}
+			}
+		})
+	})
+
+	it("returns an error if syft fails", func() {
+		s := executable.NewSyftSBOMScanner(layers, executorFunc(func(effect.Execution) error {
+			return fmt.Errorf("exit status 1")
+		}), bard.NewLogger(io.Discard))
+
+		Expect(s.ScanLaunch("/workspace", libcnb.SPDXJSON)).To(MatchError(ContainSubstring("unable to run `syft")))
+	})
+}
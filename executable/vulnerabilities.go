@@ -0,0 +1,538 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// AdvisoryDatabaseBindingType is the type of the binding holding the advisory database, OSV JSON files or zip files of
+// them such as the Maven export of osv.dev.
+const AdvisoryDatabaseBindingType = "advisory-database"
+
+// Severity is the severity of a vulnerability.
+type Severity int
+
+const (
+	SeverityNone Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "none"
+	}
+}
+
+// MarshalText encodes the severity by name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity parses a severity name, accepting moderate, as used by GitHub advisories, for medium.
+func ParseSeverity(s string) (Severity, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "none":
+		return SeverityNone, true
+	case "low":
+		return SeverityLow, true
+	case "medium", "moderate":
+		return SeverityMedium, true
+	case "high":
+		return SeverityHigh, true
+	case "critical":
+		return SeverityCritical, true
+	default:
+		return SeverityNone, false
+	}
+}
+
+// Advisory is the part of an OSV advisory used to find vulnerable libraries, see https://ossf.github.io/osv-schema.
+type Advisory struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases"`
+	Summary   string   `json:"summary"`
+	Withdrawn string   `json:"withdrawn"`
+	Severity  []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected         []AdvisoryAffected `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// AdvisoryAffected is a package affected by an Advisory and its affected versions.
+type AdvisoryAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []AdvisoryRange `json:"ranges"`
+	Versions []string        `json:"versions"`
+}
+
+// AdvisoryRange is a range of affected versions, described by the versions that introduce and fix the vulnerability.
+type AdvisoryRange struct {
+	Type   string          `json:"type"`
+	Events []AdvisoryEvent `json:"events"`
+}
+
+// AdvisoryEvent is a version that introduces or fixes a vulnerability, or the last version it affects.
+type AdvisoryEvent struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+}
+
+// version returns the version of the event, empty for the start of all versions.
+func (e AdvisoryEvent) version() string {
+	switch {
+	case e.Introduced == "0":
+		return ""
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	default:
+		return e.LastAffected
+	}
+}
+
+// LoadAdvisories reads the advisories in the files of binding. Each file is an OSV advisory, a JSON array of them or
+// a zip file of them.
+func LoadAdvisories(binding libcnb.Binding) ([]Advisory, error) {
+	var keys []string
+	for k := range binding.Secret {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var advisories []Advisory
+	for _, k := range keys {
+		b := []byte(binding.Secret[k])
+
+		if path.Ext(k) != ".zip" {
+			a, err := parseAdvisories(b)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse %s\n%w", k, err)
+			}
+			advisories = append(advisories, a...)
+			continue
+		}
+
+		z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			return nil, fmt.Errorf("unable to open %s\n%w", k, err)
+		}
+		for _, f := range z.File {
+			if path.Ext(f.Name) != ".json" {
+				continue
+			}
+
+			c, err := readEntry(f)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read %s in %s\n%w", f.Name, k, err)
+			}
+			a, err := parseAdvisories(c)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse %s in %s\n%w", f.Name, k, err)
+			}
+			advisories = append(advisories, a...)
+		}
+	}
+
+	return advisories, nil
+}
+
+func parseAdvisories(b []byte) ([]Advisory, error) {
+	b = bytes.TrimSpace(b)
+
+	if bytes.HasPrefix(b, []byte("[")) {
+		var a []Advisory
+		if err := json.Unmarshal(b, &a); err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+
+	var a Advisory
+	if err := json.Unmarshal(b, &a); err != nil {
+		return nil, err
+	}
+	return []Advisory{a}, nil
+}
+
+// Level returns the severity of the advisory, from its database specific severity or, failing that, its CVSS v3
+// vector. It returns false if the severity is not known.
+func (a Advisory) Level() (Severity, bool) {
+	if s, ok := ParseSeverity(a.DatabaseSpecific.Severity); ok && s != SeverityNone {
+		return s, true
+	}
+
+	for _, s := range a.Severity {
+		if s.Type != "CVSS_V3" {
+			continue
+		}
+		if score, ok := CVSS3BaseScore(s.Score); ok {
+			return severityOfScore(score), true
+		}
+	}
+
+	return SeverityNone, false
+}
+
+// Affects reports whether version of the package is affected, and returns the first later version that fixes it,
+// empty if none is known.
+func (a AdvisoryAffected) Affects(version string) (string, bool) {
+	for _, r := range a.Ranges {
+		if r.Type != "ECOSYSTEM" {
+			continue
+		}
+
+		events := append([]AdvisoryEvent{}, r.Events...)
+		sort.SliceStable(events, func(i, j int) bool {
+			return CompareMavenVersions(events[i].version(), events[j].version()) < 0
+		})
+
+		affected, fixed := false, ""
+		for _, e := range events {
+			switch {
+			case e.Introduced != "":
+				if e.Introduced == "0" || CompareMavenVersions(version, e.Introduced) >= 0 {
+					affected, fixed = true, ""
+				}
+			case e.Fixed != "":
+				if CompareMavenVersions(version, e.Fixed) >= 0 {
+					affected = false
+				} else if affected && fixed == "" {
+					fixed = e.Fixed
+				}
+			case e.LastAffected != "":
+				if CompareMavenVersions(version, e.LastAffected) > 0 {
+					affected = false
+				}
+			}
+		}
+		if affected {
+			return fixed, true
+		}
+	}
+
+	for _, v := range a.Versions {
+		if CompareMavenVersions(v, version) == 0 {
+			return "", true
+		}
+	}
+
+	return "", false
+}
+
+// Vulnerability is a library affected by an advisory.
+type Vulnerability struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Severity Severity `json:"severity"`
+	PURL     string   `json:"purl"`
+	Location string   `json:"location"`
+	Fixed    string   `json:"fixed,omitempty"`
+}
+
+func (v Vulnerability) String() string {
+	s := fmt.Sprintf("%s (%s) in %s at %s", v.ID, v.Severity, v.PURL, v.Location)
+	if v.Fixed != "" {
+		s += ", fixed in " + v.Fixed
+	}
+	if v.Summary != "" {
+		s += ": " + v.Summary
+	}
+	return s
+}
+
+// UncheckedPackage is a library that cannot be matched against advisories, as its Maven group or version is not known.
+type UncheckedPackage struct {
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	Location string `json:"location"`
+}
+
+func (u UncheckedPackage) String() string {
+	if u.Version == "" {
+		return fmt.Sprintf("%s at %s", u.Name, u.Location)
+	}
+	return fmt.Sprintf("%s %s at %s", u.Name, u.Version, u.Location)
+}
+
+// FindUncheckedPackages returns the packages FindVulnerabilities cannot match against advisories, those whose group or
+// version is not known, such as libraries that are only identified by their file name.
+func FindUncheckedPackages(packages []Package) []UncheckedPackage {
+	var unchecked []UncheckedPackage
+	for _, p := range packages {
+		if p.Group == "" || p.Version == "" {
+			unchecked = append(unchecked, UncheckedPackage{Name: p.Name, Version: p.Version, Location: p.Location})
+		}
+	}
+	return unchecked
+}
+
+// FindVulnerabilities returns the vulnerabilities of the packages described by advisories, most severe first. Only
+// Maven advisories are matched, against the group and name of packages whose group and version are known, see
+// FindUncheckedPackages. Advisories whose severity is not known are treated as critical.
+func FindVulnerabilities(advisories []Advisory, packages []Package) []Vulnerability {
+	type affected struct {
+		advisory Advisory
+		affected AdvisoryAffected
+	}
+
+	index := map[string][]affected{}
+	for _, a := range advisories {
+		if a.Withdrawn != "" {
+			continue
+		}
+		for _, f := range a.Affected {
+			if strings.EqualFold(f.Package.Ecosystem, "Maven") {
+				index[f.Package.Name] = append(index[f.Package.Name], affected{a, f})
+			}
+		}
+	}
+
+	var vulnerabilities []Vulnerability
+	for _, p := range packages {
+		if p.Group == "" || p.Version == "" {
+			continue
+		}
+
+		seen := map[string]bool{}
+		for _, a := range index[p.Group+":"+p.Name] {
+			fixed, ok := a.affected.Affects(p.Version)
+			if !ok || seen[a.advisory.ID] {
+				continue
+			}
+			seen[a.advisory.ID] = true
+
+			severity, ok := a.advisory.Level()
+			if !ok {
+				severity = SeverityCritical
+			}
+
+			vulnerabilities = append(vulnerabilities, Vulnerability{
+				ID:       a.advisory.ID,
+				Aliases:  a.advisory.Aliases,
+				Summary:  a.advisory.Summary,
+				Severity: severity,
+				PURL:     p.PURL(),
+				Location: p.Location,
+				Fixed:    fixed,
+			})
+		}
+	}
+
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		if vulnerabilities[i].Severity != vulnerabilities[j].Severity {
+			return vulnerabilities[i].Severity > vulnerabilities[j].Severity
+		}
+		if vulnerabilities[i].Location != vulnerabilities[j].Location {
+			return vulnerabilities[i].Location < vulnerabilities[j].Location
+		}
+		return vulnerabilities[i].ID < vulnerabilities[j].ID
+	})
+
+	return vulnerabilities
+}
+
+// cvss3Metrics are the weights of the CVSS v3 base metrics. The privileges required weigh more if the scope changes.
+var cvss3Metrics = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// CVSS3BaseScore returns the base score of a CVSS v3 vector such as CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H, or
+// false if it is not a valid vector.
+func CVSS3BaseScore(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, false
+	}
+
+	values := map[string]string{}
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, ":"); ok {
+			values[k] = v
+		}
+	}
+
+	scope := values["S"]
+	if scope != "U" && scope != "C" {
+		return 0, false
+	}
+
+	w := map[string]float64{}
+	for k, m := range cvss3Metrics {
+		v, ok := m[values[k]]
+		if !ok {
+			return 0, false
+		}
+		w[k] = v
+	}
+	if scope == "C" {
+		w["PR"] = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}[values["PR"]]
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if scope == "C" {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if scope == "C" {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp rounds up to one decimal place as defined by CVSS v3.1, avoiding floating point errors.
+func roundUp(f float64) float64 {
+	i := int(math.Round(f * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
+
+func severityOfScore(score float64) Severity {
+	switch {
+	case score >= 9:
+		return SeverityCritical
+	case score >= 7:
+		return SeverityHigh
+	case score >= 4:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityNone
+	}
+}
+
+// resolveVulnerabilitySeverity returns the severity of $BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY, the least severe
+// vulnerability that fails the build. SeverityNone only warns.
+func resolveVulnerabilitySeverity(cr libpak.ConfigurationResolver) (Severity, error) {
+	s, _ := cr.Resolve("BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY")
+	if s == "" {
+		return SeverityHigh, nil
+	}
+
+	severity, ok := ParseSeverity(s)
+	if !ok {
+		return SeverityNone, fmt.Errorf("$BP_EXECUTABLE_JAR_VULNERABILITY_SEVERITY must be low, medium, high, critical or none, not %q", s)
+	}
+	return severity, nil
+}
+
+// VulnerabilityReport contributes a launch layer holding vulnerabilities.json, the vulnerabilities found in the
+// libraries of the application and the libraries that could not be checked.
+type VulnerabilityReport struct {
+	Vulnerabilities  []Vulnerability
+	Unchecked        []UncheckedPackage
+	LayerContributor libpak.LayerContributor
+	Logger           bard.Logger
+}
+
+func NewVulnerabilityReport(vulnerabilities []Vulnerability, unchecked []UncheckedPackage) VulnerabilityReport {
+	return VulnerabilityReport{Vulnerabilities: vulnerabilities, Unchecked: unchecked}
+}
+
+// Report returns the contents of vulnerabilities.json.
+func (v VulnerabilityReport) Report() ([]byte, error) {
+	report := struct {
+		Vulnerabilities []Vulnerability    `json:"vulnerabilities"`
+		Unchecked       []UncheckedPackage `json:"unchecked"`
+	}{v.Vulnerabilities, v.Unchecked}
+
+	if report.Vulnerabilities == nil {
+		report.Vulnerabilities = []Vulnerability{}
+	}
+	if report.Unchecked == nil {
+		report.Unchecked = []UncheckedPackage{}
+	}
+
+	return json.MarshalIndent(report, "", "  ")
+}
+
+func (v VulnerabilityReport) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	report, err := v.Report()
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create vulnerability report\n%w", err)
+	}
+
+	contributor := libpak.NewLayerContributor(
+		"Vulnerability Report",
+		map[string]interface{}{"sha256": fmt.Sprintf("%x", sha256.Sum256(report))},
+		libcnb.LayerTypes{Launch: true},
+	)
+	contributor.Logger = v.Logger
+
+	return contributor.Contribute(layer, func() (libcnb.Layer, error) {
+		file := filepath.Join(layer.Path, "vulnerabilities.json")
+		if err := os.WriteFile(file, report, 0644); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to write %s\n%w", file, err)
+		}
+
+		return layer, nil
+	})
+}
+
+func (VulnerabilityReport) Name() string {
+	return "vulnerabilities"
+}
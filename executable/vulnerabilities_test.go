@@ -0,0 +1,207 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+// log4Shell is the OSV advisory of CVE-2021-44228, trimmed to the fields that are read
+const log4Shell = `{
+  "id": "GHSA-jfh8-c2jp-5v3q",
+  "aliases": ["CVE-2021-44228"],
+  "summary": "Remote code injection in Log4j",
+  "affected": [{
+    "package": {"ecosystem": "Maven", "name": "org.apache.logging.log4j:log4j-core"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [
+      {"introduced": "2.13.0"}, {"fixed": "2.15.0"},
+      {"introduced": "2.0-beta9"}, {"fixed": "2.3.1"},
+      {"introduced": "2.4"}, {"fixed": "2.12.2"}
+    ]}]
+  }],
+  "database_specific": {"severity": "CRITICAL"}
+}`
+
+// advisory returns an OSV advisory of a vulnerability in versions of org.example:lib
+func advisory(id string, events string, severity string) string {
+	return `{
+  "id": "` + id + `",
+  "affected": [{"package": {"ecosystem": "Maven", "name": "org.example:lib"}, "ranges": [{"type": "ECOSYSTEM", "events": ` + events + `}]}],
+  "severity": [{"type": "CVSS_V3", "score": "` + severity + `"}]
+}`
+}
+
+func testVulnerabilities(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	load := func(secret map[string]string) []executable.Advisory {
+		advisories, err := executable.LoadAdvisories(libcnb.NewBinding("advisories", t.TempDir(), secret))
+		Expect(err).NotTo(HaveOccurred())
+		return advisories
+	}
+
+	context("LoadAdvisories", func() {
+		it("reads advisories, arrays of advisories and zip files of advisories", func() {
+			file := filepath.Join(t.TempDir(), "all.zip")
+			CreateZIP(t, file, map[string]string{
+				"GHSA-1.json": advisory("GHSA-1", "[]", ""),
+				"README.md":   "not an advisory",
+			})
+			b, err := os.ReadFile(file)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(load(map[string]string{
+				"all.zip":      string(b),
+				"log4j.json":   log4Shell,
+				"example.json": "[" + advisory("GHSA-2", "[]", "") + "," + advisory("GHSA-3", "[]", "") + "]",
+			})).To(ConsistOf(
+				HaveField("ID", "GHSA-1"),
+				HaveField("ID", "GHSA-2"),
+				HaveField("ID", "GHSA-3"),
+				HaveField("ID", "GHSA-jfh8-c2jp-5v3q"),
+			))
+		})
+
+		it("returns an error for an invalid advisory", func() {
+			_, err := executable.LoadAdvisories(libcnb.NewBinding("advisories", t.TempDir(), map[string]string{"broken.json": "{"}))
+			Expect(err).To(MatchError(ContainSubstring("unable to parse broken.json")))
+		})
+	})
+
+	context("FindVulnerabilities", func() {
+		it("finds the vulnerable versions of libraries", func() {
+			advisories := load(map[string]string{"log4j.json": log4Shell})
+
+			Expect(executable.FindVulnerabilities(advisories, []executable.Package{
+				{Group: "org.apache.logging.log4j", Name: "log4j-core", Version: "2.14.1", Location: "a.jar"},
+				{Group: "org.apache.logging.log4j", Name: "log4j-core", Version: "2.3", Location: "b.jar"},
+				{Group: "org.apache.logging.log4j", Name: "log4j-core", Version: "2.12.2", Location: "c.jar"},
+				{Group: "org.apache.logging.log4j", Name: "log4j-core", Version: "2.17.1", Location: "d.jar"},
+				{Group: "org.apache.logging.log4j", Name: "log4j-api", Version: "2.14.1", Location: "e.jar"},
+				{Name: "log4j-core", Version: "2.14.1", Location: "f.jar"},
+			})).To(Equal([]executable.Vulnerability{
+				{
+					ID:       "GHSA-jfh8-c2jp-5v3q",
+					Aliases:  []string{"CVE-2021-44228"},
+					Summary:  "Remote code injection in Log4j",
+					Severity: executable.SeverityCritical,
+					PURL:     "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1",
+					Location: "a.jar",
+					Fixed:    "2.15.0",
+				},
+				{
+					ID:       "GHSA-jfh8-c2jp-5v3q",
+					Aliases:  []string{"CVE-2021-44228"},
+					Summary:  "Remote code injection in Log4j",
+					Severity: executable.SeverityCritical,
+					PURL:     "pkg:maven/org.apache.logging.log4j/log4j-core@2.3",
+					Location: "b.jar",
+					Fixed:    "2.3.1",
+				},
+			}))
+		})
+
+		it("matches listed versions, last affected versions and unbounded ranges", func() {
+			advisories := load(map[string]string{"example.json": `[
+  {"id": "LISTED", "affected": [{"package": {"ecosystem": "Maven", "name": "org.example:lib"}, "versions": ["1.0.0"]}]},
+  ` + advisory("LAST", `[{"introduced": "0"}, {"last_affected": "1.0"}]`, "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N") + `,
+  ` + advisory("OPEN", `[{"introduced": "0.9"}]`, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H") + `,
+  ` + advisory("WITHDRAWN", `[{"introduced": "0"}]`, "") + `
+]`})
+			advisories[3].Withdrawn = "2022-01-01T00:00:00Z"
+
+			Expect(executable.FindVulnerabilities(advisories, []executable.Package{
+				{Group: "org.example", Name: "lib", Version: "1.0", Location: "lib.jar"},
+			})).To(Equal([]executable.Vulnerability{
+				{ID: "LISTED", Severity: executable.SeverityCritical, PURL: "pkg:maven/org.example/lib@1.0", Location: "lib.jar"},
+				{ID: "OPEN", Severity: executable.SeverityCritical, PURL: "pkg:maven/org.example/lib@1.0", Location: "lib.jar"},
+				{ID: "LAST", Severity: executable.SeverityMedium, PURL: "pkg:maven/org.example/lib@1.0", Location: "lib.jar"},
+			}))
+		})
+	})
+
+	it("finds the libraries that cannot be checked", func() {
+		unchecked := executable.FindUncheckedPackages([]executable.Package{
+			{Group: "org.example", Name: "lib", Version: "1.0", Location: "lib.jar"},
+			{Name: "renamed", Version: "2.0", Location: "renamed-2.0.jar"},
+			{Group: "org.example", Name: "snapshot", Location: "snapshot.jar"},
+		})
+
+		Expect(unchecked).To(Equal([]executable.UncheckedPackage{
+			{Name: "renamed", Version: "2.0", Location: "renamed-2.0.jar"},
+			{Name: "snapshot", Location: "snapshot.jar"},
+		}))
+		Expect(unchecked[0].String()).To(Equal("renamed 2.0 at renamed-2.0.jar"))
+	})
+
+	it("computes CVSS v3 base scores", func() {
+		for vector, score := range map[string]float64{
+			"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H": 10.0,
+			"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+			"CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N": 5.9,
+			"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:L/I:N/A:N": 3.3,
+			"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:L/I:L/A:N": 6.4,
+			"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:N/I:N/A:N": 0,
+		} {
+			s, ok := executable.CVSS3BaseScore(vector)
+			Expect(ok).To(BeTrue(), vector)
+			Expect(s).To(Equal(score), vector)
+		}
+
+		_, ok := executable.CVSS3BaseScore("CVSS:2.0/AV:N")
+		Expect(ok).To(BeFalse())
+	})
+
+	it("contributes the vulnerability report", func() {
+		layers := libcnb.Layers{Path: t.TempDir()}
+		layer, err := layers.Layer("vulnerabilities")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = executable.NewVulnerabilityReport([]executable.Vulnerability{
+			{ID: "GHSA-1", Severity: executable.SeverityHigh, PURL: "pkg:maven/org.example/lib@1.0", Location: "lib.jar", Fixed: "1.1"},
+		}, []executable.UncheckedPackage{
+			{Name: "unknown", Location: "unknown.jar"},
+		}).Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(layer.Launch).To(BeTrue())
+
+		b, err := os.ReadFile(filepath.Join(layer.Path, "vulnerabilities.json"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var report map[string][]map[string]interface{}
+		Expect(json.Unmarshal(b, &report)).To(Succeed())
+		Expect(report).To(Equal(map[string][]map[string]interface{}{
+			"vulnerabilities": {
+				{"id": "GHSA-1", "severity": "high", "purl": "pkg:maven/org.example/lib@1.0", "location": "lib.jar", "fixed": "1.1"},
+			},
+			"unchecked": {
+				{"name": "unknown", "location": "unknown.jar"},
+			},
+		}))
+	})
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+const (
+	WatcherWatchexec = "watchexec"
+	WatcherBuiltin   = "builtin"
+)
+
+// Watcher contributes the built-in file watcher, an alternative to watchexec for the reload process.
+type Watcher struct {
+	Source           string
+	Version          string
+	LayerContributor libpak.LayerContributor
+	Logger           bard.Logger
+}
+
+// NewWatcher creates a Watcher that contributes the watcher binary shipped in buildpack.
+func NewWatcher(buildpack libcnb.Buildpack) Watcher {
+	return Watcher{
+		Source:  filepath.Join(buildpack.Path, "bin", "watcher"),
+		Version: buildpack.Info.Version,
+	}
+}
+
+// Command returns the path of the watcher binary in layer.
+func (Watcher) Command(layer libcnb.Layer) string {
+	return filepath.Join(layer.Path, "bin", "watcher")
+}
+
+func (w Watcher) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	contributor := libpak.NewLayerContributor(
+		"Watcher",
+		map[string]interface{}{"version": w.Version},
+		libcnb.LayerTypes{Launch: true},
+	)
+	contributor.Logger = w.Logger
+
+	return contributor.Contribute(layer, func() (libcnb.Layer, error) {
+		in, err := os.Open(w.Source)
+		if err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to open %s\n%w", w.Source, err)
+		}
+		defer in.Close()
+
+		if err := sherpa.CopyFile(in, w.Command(layer)); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to copy %s\n%w", w.Source, err)
+		}
+
+		return layer, nil
+	})
+}
+
+func (Watcher) Name() string {
+	return "watcher"
+}
+
+// resolveWatcher returns the configured $BP_LIVE_RELOAD_WATCHER.
+func resolveWatcher(cr libpak.ConfigurationResolver) (string, error) {
+	switch s, _ := cr.Resolve("BP_LIVE_RELOAD_WATCHER"); s {
+	case "", WatcherWatchexec:
+		return WatcherWatchexec, nil
+	case WatcherBuiltin:
+		return WatcherBuiltin, nil
+	default:
+		return "", fmt.Errorf("$BP_LIVE_RELOAD_WATCHER must be %s or %s, not %q", WatcherWatchexec, WatcherBuiltin, s)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executable_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/executable"
+)
+
+func testWatcher(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx libcnb.BuildContext
+	)
+
+	it.Before(func() {
+		ctx.Buildpack.Path = t.TempDir()
+		ctx.Buildpack.Info.Version = "1.2.3"
+		ctx.Layers.Path = t.TempDir()
+
+		Expect(os.MkdirAll(filepath.Join(ctx.Buildpack.Path, "bin"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "bin", "watcher"), []byte("test-watcher"), 0755)).To(Succeed())
+	})
+
+	it("contributes the watcher for launch", func() {
+		w := executable.NewWatcher(ctx.Buildpack)
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = w.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.Launch).To(BeTrue())
+		Expect(layer.Build).To(BeFalse())
+		Expect(w.Command(layer)).To(Equal(filepath.Join(layer.Path, "bin", "watcher")))
+
+		fi, err := os.Stat(w.Command(layer))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0755)))
+		Expect(os.ReadFile(w.Command(layer))).To(Equal([]byte("test-watcher")))
+	})
+
+	it("returns an error if the buildpack has no watcher", func() {
+		Expect(os.Remove(filepath.Join(ctx.Buildpack.Path, "bin", "watcher"))).To(Succeed())
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = executable.NewWatcher(ctx.Buildpack).Contribute(layer)
+		Expect(err).To(MatchError(ContainSubstring("unable to open")))
+	})
+}
@@ -4,61 +4,196 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"sort"
 )
 
+// SymlinkPolicy controls how WalkDir treats symbolic links.
+type SymlinkPolicy int
+
+const (
+	// SymlinkReport passes symbolic links to the walk function without following them.
+	SymlinkReport SymlinkPolicy = iota
+
+	// SymlinkIgnore skips symbolic links entirely.
+	SymlinkIgnore
+
+	// SymlinkFollow follows symbolic links, descending into linked directories unless doing so would revisit one of
+	// their own ancestors. Links that are not followed, because they are broken or would form a cycle, are reported
+	// as links.
+	SymlinkFollow
+)
+
+// WalkOptions configures WalkDir. The zero value reports symbolic links, has no depth limit and passes directory
+// read errors to the walk function.
+type WalkOptions struct {
+	// Symlinks is the policy applied to symbolic links below the root.
+	Symlinks SymlinkPolicy
+
+	// MaxDepth limits how deep the walk descends, the root being at depth 0. Directories at MaxDepth are passed to
+	// the walk function but not read. A value of zero or less means no limit.
+	MaxDepth int
+
+	// OnDirError, if set, is called when a directory cannot be read after the walk function has visited it. Returning
+	// nil skips the directory and continues the walk, any other error stops it. If unset, the walk function is called
+	// with the read error instead of visiting the directory.
+	OnDirError func(path string, err error) error
+}
+
+// Walk walks the file tree rooted at root in breadth-first order, calling walkFn for each file or directory in the
+// tree, including root. Entries within a directory are visited in lexical order and symbolic links are not followed.
 func Walk(root string, walkFn filepath.WalkFunc) error {
-	type node struct {
-		next  *node
-		value string
+	return WalkFS(os.DirFS(root), ".", func(p string, info fs.FileInfo, err error) error {
+		if p == "." {
+			return walkFn(root, info, err)
+		}
+		return walkFn(filepath.Join(root, filepath.FromSlash(p)), info, err)
+	})
+}
+
+// WalkFS is like Walk but walks the tree rooted at root within fsys. Paths passed to walkFn are slash-separated
+// and relative to fsys, as required by fs.FS. Symbolic links are not followed if fsys implements fs.ReadLinkFS.
+func WalkFS(fsys fs.FS, root string, walkFn filepath.WalkFunc) error {
+	return WalkDir(fsys, root, WalkOptions{}, func(p string, d fs.DirEntry, err error) error {
+		var fi fs.FileInfo
+		if d != nil {
+			var infoErr error
+			if fi, infoErr = d.Info(); err == nil {
+				err = infoErr
+			}
+		}
+		return walkFn(p, fi, err)
+	})
+}
+
+// WalkDir walks the file tree rooted at root within fsys in breadth-first order, calling fn for each file or
+// directory in the tree, including root. Entries within a directory are visited in lexical order. Unlike Walk it
+// works on fs.DirEntry values and so does not stat every entry it visits.
+//
+// As with fs.WalkDir, fn may return fs.SkipDir to skip a directory or fs.SkipAll to stop the walk early. If the root
+// cannot be read, fn is called once with a nil fs.DirEntry and the error.
+func WalkDir(fsys fs.FS, root string, opts WalkOptions, fn fs.WalkDirFunc) error {
+	fi, err := fs.Lstat(fsys, root)
+	if err != nil {
+		return skipAll(fn(root, nil, err))
 	}
+
 	var (
-		head = &node{value: root}
+		head = &walkNode{value: root, entry: fs.FileInfoToDirEntry(fi), real: root}
 		tail = head
 	)
-	var err error
 	for ; head != nil; head = head.next {
-		p := head.value
-		var fi fs.FileInfo
-		fi, err = os.Lstat(p)
-		if err != nil || !fi.IsDir() {
-			err = walkFn(p, fi, err)
-			if err != nil {
+		p, d := head.value, head.entry
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			if opts.Symlinks == SymlinkIgnore && head.parent != nil {
+				continue
+			}
+			if opts.Symlinks == SymlinkFollow {
+				d = head.follow(fsys)
+			}
+		}
+
+		if !d.IsDir() {
+			if err = fn(p, d, nil); err != nil {
 				break
 			}
 			continue
 		}
-		var names []string
-		names, err = readDirNames(p)
-		err = walkFn(p, fi, err)
+
+		var entries []fs.DirEntry
+		var readErr error
+		if opts.MaxDepth <= 0 || head.depth < opts.MaxDepth {
+			entries, readErr = fs.ReadDir(fsys, p)
+		}
+
+		if readErr != nil && opts.OnDirError == nil {
+			err = fn(p, d, readErr)
+		} else if err = fn(p, d, nil); err == nil && readErr != nil {
+			entries = nil
+			err = opts.OnDirError(p, readErr)
+		}
 		if err != nil {
-			if errors.Is(err, filepath.SkipDir) {
+			if errors.Is(err, fs.SkipDir) {
+				err = nil
 				continue
 			}
 			break
 		}
-		for _, name := range names {
-			tail.next = &node{value: filepath.Join(p, name)}
+
+		for _, e := range entries {
+			tail.next = &walkNode{
+				parent: head,
+				value:  path.Join(p, e.Name()),
+				entry:  e,
+				depth:  head.depth + 1,
+				real:   path.Join(head.real, e.Name()),
+			}
 			tail = tail.next
 		}
 	}
-	if errors.Is(err, filepath.SkipAll) {
-		return nil
-	}
-	return err
+
+	return skipAll(err)
 }
 
-func readDirNames(dirname string) ([]string, error) {
-	f, err := os.Open(dirname)
+type walkNode struct {
+	next   *walkNode
+	parent *walkNode
+	value  string
+	entry  fs.DirEntry
+	depth  int
+
+	// real is the path of the node with symbolic links resolved, as far as that is possible within the fs.FS
+	real string
+
+	// target describes what a followed symbolic link points to
+	target fs.FileInfo
+}
+
+// follow resolves the symbolic link at n, returning the entry it points to. Links that are broken or point to one
+// of their own ancestors are returned unchanged.
+func (n *walkNode) follow(fsys fs.FS) fs.DirEntry {
+	fi, err := fs.Stat(fsys, n.value)
 	if err != nil {
-		return nil, err
+		return n.entry
 	}
-	names, err := f.Readdirnames(-1)
-	f.Close()
-	if err != nil {
-		return nil, err
+
+	for i := 0; i < 255; i++ {
+		target, err := fs.ReadLink(fsys, n.real)
+		if err != nil || path.IsAbs(target) {
+			break
+		}
+		n.real = path.Join(path.Dir(n.real), target)
 	}
-	sort.Strings(names)
-	return names, nil
+
+	if fi.IsDir() {
+		for a := n.parent; a != nil; a = a.parent {
+			if a.real == n.real || a.sameFile(fi) {
+				return n.entry
+			}
+		}
+	}
+
+	n.target = fi
+	return fs.FileInfoToDirEntry(fi)
+}
+
+// sameFile reports whether n refers to the file described by fi. It only works for fs.FS implementations backed by
+// the operating system, see os.SameFile.
+func (n *walkNode) sameFile(fi fs.FileInfo) bool {
+	info := n.target
+	if info == nil {
+		var err error
+		if info, err = n.entry.Info(); err != nil {
+			return false
+		}
+	}
+	return os.SameFile(info, fi)
+}
+
+func skipAll(err error) error {
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
 }
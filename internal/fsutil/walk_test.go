@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/executable-jar/v6/internal/fsutil"
@@ -161,6 +162,194 @@ func testWalk(t *testing.T, context spec.G, it spec.S) {
 		Expect(err).To(MatchError(errSentinel))
 		Expect(content).To(Equal(contentC1A2A3))
 	})
+
+	context("fs.FS", func() {
+		it("walks breadth-first with slash-separated paths", func() {
+			fsys := fstest.MapFS{
+				"b/c/d.jar": {},
+				"b/a.jar":   {},
+				"a.jar":     {},
+			}
+
+			var paths []string
+			err := fsutil.WalkFS(fsys, ".", func(path string, fi fs.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				paths = append(paths, path)
+				return nil
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(Equal([]string{".", "a.jar", "b", "b/a.jar", "b/c", "b/c/d.jar"}))
+		})
+
+		it("matches Walk on a directory", func() {
+			var viaFS, viaDir []fileInfo
+
+			Expect(fsutil.WalkFS(os.DirFS(root), ".", func(path string, fi fs.FileInfo, err error) error {
+				if err != nil {
+					return nil
+				}
+				viaFS = append(viaFS, fileInfo{Path: filepath.Join(root, filepath.FromSlash(path)), Type: fi.Mode().Type()})
+				return nil
+			})).To(Succeed())
+
+			Expect(fsutil.Walk(root, func(path string, fi fs.FileInfo, err error) error {
+				if err != nil {
+					return nil
+				}
+				viaDir = append(viaDir, fileInfo{Path: filepath.Clean(path), Type: fi.Mode().Type()})
+				return nil
+			})).To(Succeed())
+
+			Expect(viaFS).To(Equal(viaDir))
+		})
+	})
+
+	context("WalkDir", func() {
+		var walk = func(opts fsutil.WalkOptions) ([]string, error) {
+			var paths []string
+			err := fsutil.WalkDir(os.DirFS(root), ".", opts, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return nil
+				}
+				if d.IsDir() {
+					path += "/"
+				} else if d.Type()&fs.ModeSymlink != 0 {
+					path += "@"
+				}
+				paths = append(paths, path)
+				return nil
+			})
+			return paths, err
+		}
+
+		it("reports symbolic links by default", func() {
+			paths, err := walk(fsutil.WalkOptions{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(ContainElement("a.lnk@"))
+			Expect(paths).NotTo(ContainElement(HavePrefix("a.lnk/")))
+		})
+
+		it("ignores symbolic links", func() {
+			paths, err := walk(fsutil.WalkOptions{Symlinks: fsutil.SymlinkIgnore})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).NotTo(ContainElement(HavePrefix("a.lnk")))
+		})
+
+		it("follows symbolic links", func() {
+			paths, err := walk(fsutil.WalkOptions{Symlinks: fsutil.SymlinkFollow})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(ContainElements("a.lnk/", "a.lnk/a2/", "a.lnk/a2/"+filename))
+		})
+
+		it("does not follow symbolic links into a cycle", func() {
+			Expect(os.Symlink("..", filepath.Join(root, "c1", "up.lnk"))).To(Succeed())
+			Expect(os.Symlink(filepath.Join(root, "c1"), filepath.Join(root, "c1", "a2", "abs.lnk"))).To(Succeed())
+
+			paths, err := walk(fsutil.WalkOptions{Symlinks: fsutil.SymlinkFollow})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(ContainElements("c1/up.lnk@", "c1/a2/abs.lnk@"))
+			Expect(paths).NotTo(ContainElement(HavePrefix("c1/up.lnk/")))
+			Expect(paths).NotTo(ContainElement(HavePrefix("c1/a2/abs.lnk/")))
+		})
+
+		it("follows symbolic links in an fs.FS without operating system support", func() {
+			fsys := fstest.MapFS{
+				"dist/app.jar":  {},
+				"dist/loop.lnk": {Data: []byte(".."), Mode: fs.ModeSymlink},
+				"lib":           {Data: []byte("dist"), Mode: fs.ModeSymlink},
+			}
+
+			var paths []string
+			err := fsutil.WalkDir(fsys, ".", fsutil.WalkOptions{Symlinks: fsutil.SymlinkFollow}, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				paths = append(paths, path)
+				return nil
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(Equal([]string{".", "dist", "lib", "dist/app.jar", "dist/loop.lnk", "lib/app.jar", "lib/loop.lnk"}))
+		})
+
+		it("limits the depth", func() {
+			paths, err := walk(fsutil.WalkOptions{MaxDepth: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(Equal([]string{"./", "a.lnk@", "a1/", "b1/", "c1/"}))
+		})
+
+		it("calls the directory error callback", func() {
+			var failed []string
+			var paths []string
+			err := fsutil.WalkDir(unreadableFS{"b/c"}, ".", fsutil.WalkOptions{
+				OnDirError: func(path string, err error) error {
+					failed = append(failed, path)
+					return nil
+				},
+			}, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				paths = append(paths, path)
+				return nil
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failed).To(Equal([]string{"b/c"}))
+			Expect(paths).To(Equal([]string{".", "a", "b", "a/d.jar", "b/c"}))
+		})
+
+		it("passes directory errors to the walk function by default", func() {
+			err := fsutil.WalkDir(unreadableFS{"b/c"}, ".", fsutil.WalkOptions{}, func(path string, d fs.DirEntry, err error) error {
+				return err
+			})
+
+			Expect(err).To(MatchError(errSentinel))
+		})
+
+		it("stops when the directory error callback fails", func() {
+			err := fsutil.WalkDir(unreadableFS{"b/c"}, ".", fsutil.WalkOptions{
+				OnDirError: func(path string, err error) error {
+					return err
+				},
+			}, func(path string, d fs.DirEntry, err error) error {
+				return nil
+			})
+
+			Expect(err).To(MatchError(errSentinel))
+		})
+	})
+}
+
+// unreadableFS fails to read the named directory. Unlike chmod this also works when the tests run as root.
+type unreadableFS struct {
+	name string
+}
+
+func (u unreadableFS) files() fstest.MapFS {
+	return fstest.MapFS{
+		"a/d.jar":   {},
+		"b/c/e.jar": {},
+	}
+}
+
+func (u unreadableFS) Open(name string) (fs.File, error) {
+	return u.files().Open(name)
+}
+
+func (u unreadableFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == u.name {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errSentinel}
+	}
+	return u.files().ReadDir(name)
 }
 
 func sortBFSOrder(files []fileInfo) {
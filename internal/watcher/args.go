@@ -0,0 +1,146 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package watcher
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	DefaultDebounce    = 50 * time.Millisecond
+	DefaultStopSignal  = unix.SIGTERM
+	DefaultStopTimeout = 10 * time.Second
+)
+
+// ParseArguments creates a Watcher from the subset of watchexec arguments generated by executable.LiveReload.
+func ParseArguments(args []string) (Watcher, error) {
+	w := Watcher{
+		Debounce:    DefaultDebounce,
+		StopSignal:  DefaultStopSignal,
+		StopTimeout: DefaultStopTimeout,
+	}
+
+	shell := false
+	for i := 0; i < len(args); i++ {
+		name, value, _ := strings.Cut(args[i], "=")
+
+		switch name {
+		case "--":
+			w.Command = args[i+1:]
+			i = len(args)
+		case "-r", "--restart":
+		case "--clear":
+			w.ClearScreen = true
+		case "--shell":
+			switch value {
+			case "none":
+				shell = false
+			case "sh":
+				shell = true
+			default:
+				return Watcher{}, fmt.Errorf("unsupported shell %q", value)
+			}
+		case "--watch":
+			w.Paths = append(w.Paths, value)
+		case "--ignore":
+			w.Ignore = append(w.Ignore, value)
+		case "--no-vcs-ignore":
+			// VCS ignore files are only read if they are passed with --ignore-file
+		case "--ignore-file":
+			patterns, err := readIgnoreFile(value)
+			if err != nil {
+				return Watcher{}, err
+			}
+			w.Ignore = append(w.Ignore, patterns...)
+		case "--debounce":
+			d, err := parseDuration(value, time.Millisecond)
+			if err != nil {
+				return Watcher{}, fmt.Errorf("unable to parse debounce %q\n%w", value, err)
+			}
+			w.Debounce = d
+		case "--stop-timeout":
+			d, err := parseDuration(value, time.Second)
+			if err != nil {
+				return Watcher{}, fmt.Errorf("unable to parse stop timeout %q\n%w", value, err)
+			}
+			w.StopTimeout = d
+		case "--stop-signal":
+			s := unix.SignalNum(strings.ToUpper(value))
+			if s == 0 {
+				s = unix.SignalNum("SIG" + strings.ToUpper(value))
+			}
+			if s == 0 {
+				return Watcher{}, fmt.Errorf("unknown signal %q", value)
+			}
+			w.StopSignal = s
+		default:
+			return Watcher{}, fmt.Errorf("unsupported argument %q", args[i])
+		}
+	}
+
+	if len(w.Command) == 0 {
+		return Watcher{}, fmt.Errorf("no command to run")
+	}
+	if len(w.Paths) == 0 {
+		w.Paths = []string{"."}
+	}
+	if shell {
+		w.Command = []string{"sh", "-c", strings.Join(w.Command, " ")}
+	}
+
+	return w, nil
+}
+
+// parseDuration parses a number of units or a duration such as 500ms. As with watchexec, the unit of a number is
+// milliseconds for --debounce and seconds for --stop-timeout.
+func parseDuration(s string, unit time.Duration) (time.Duration, error) {
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return time.Duration(n) * unit, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// readIgnoreFile reads the patterns of a .gitignore file. Negated patterns are not supported and are skipped.
+func readIgnoreFile(file string) ([]string, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer in.Close()
+
+	var patterns []string
+	s := bufio.NewScanner(in)
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") || strings.HasPrefix(l, "!") {
+			continue
+		}
+		patterns = append(patterns, strings.TrimSuffix(l, "/"))
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	return patterns, nil
+}
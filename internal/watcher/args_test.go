@@ -0,0 +1,136 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package watcher_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/internal/watcher"
+)
+
+func testArguments(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		path = t.TempDir()
+	})
+
+	it("uses defaults", func() {
+		w, err := watcher.ParseArguments([]string{"-r", "--shell=none", "--", "java", "test-class"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(w).To(Equal(watcher.Watcher{
+			Command:     []string{"java", "test-class"},
+			Paths:       []string{"."},
+			Debounce:    watcher.DefaultDebounce,
+			StopSignal:  watcher.DefaultStopSignal,
+			StopTimeout: watcher.DefaultStopTimeout,
+		}))
+	})
+
+	it("parses watchexec arguments", func() {
+		Expect(os.WriteFile(filepath.Join(path, ".gitignore"), []byte("# comment\n\n*.log\n!keep.log\nbuild/\n"), 0644)).To(Succeed())
+
+		w, err := watcher.ParseArguments([]string{
+			"-r",
+			"--shell=none",
+			"--watch=/workspace/a",
+			"--watch=/workspace/b",
+			"--ignore=*.tmp",
+			"--ignore-file=" + filepath.Join(path, ".gitignore"),
+			"--no-vcs-ignore",
+			"--debounce=500",
+			"--stop-signal=SIGINT",
+			"--stop-timeout=2s",
+			"--clear",
+			"--",
+			"java", "-jar", "test.jar",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(w).To(Equal(watcher.Watcher{
+			Command:     []string{"java", "-jar", "test.jar"},
+			Paths:       []string{"/workspace/a", "/workspace/b"},
+			Ignore:      []string{"*.tmp", "*.log", "build"},
+			Debounce:    500 * time.Millisecond,
+			StopSignal:  syscall.SIGINT,
+			StopTimeout: 2 * time.Second,
+			ClearScreen: true,
+		}))
+	})
+
+	it("reads numbers as watchexec does", func() {
+		w, err := watcher.ParseArguments([]string{"--debounce=250", "--stop-timeout=3", "--", "java"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Debounce).To(Equal(250 * time.Millisecond))
+		Expect(w.StopTimeout).To(Equal(3 * time.Second))
+	})
+
+	it("accepts signal names without SIG", func() {
+		w, err := watcher.ParseArguments([]string{"--stop-signal=hup", "--", "java"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.StopSignal).To(Equal(syscall.SIGHUP))
+	})
+
+	it("runs the command with sh", func() {
+		w, err := watcher.ParseArguments([]string{"--shell=sh", "--", "echo hello; exec java test-class"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Command).To(Equal([]string{"sh", "-c", "echo hello; exec java test-class"}))
+	})
+
+	it("returns errors for invalid arguments", func() {
+		for _, args := range [][]string{
+			{},
+			{"--unknown", "--", "java"},
+			{"--shell=bash", "--", "java"},
+			{"--debounce=soon", "--", "java"},
+			{"--stop-timeout=-", "--", "java"},
+			{"--stop-signal=SIGNOPE", "--", "java"},
+			{"--ignore-file=" + filepath.Join(path, "missing"), "--", "java"},
+			{"--watch=/workspace"},
+		} {
+			_, err := watcher.ParseArguments(args)
+			Expect(err).To(HaveOccurred(), "%v", args)
+		}
+	})
+
+	it("matches ignore patterns", func() {
+		w := watcher.Watcher{Paths: []string{"/workspace"}, Ignore: []string{"*.log", "target/**", "node_modules", "/tmp/*.swp"}}
+
+		Expect(w.Ignored("/workspace/app.log")).To(BeTrue())
+		Expect(w.Ignored("/workspace/a/b/app.log")).To(BeTrue())
+		Expect(w.Ignored("/workspace/target")).To(BeTrue())
+		Expect(w.Ignored("/workspace/target/classes/A.class")).To(BeTrue())
+		Expect(w.Ignored("/workspace/web/node_modules/x/index.js")).To(BeTrue())
+		Expect(w.Ignored("/workspace/tmp/a.swp")).To(BeTrue())
+
+		Expect(w.Ignored("/workspace/A.class")).To(BeFalse())
+		Expect(w.Ignored("/workspace/targets/A.class")).To(BeFalse())
+		Expect(w.Ignored("/workspace/a/tmp/a.swp")).To(BeFalse())
+	})
+}
@@ -0,0 +1,6 @@
+package watcher
+
+// Ignored exposes ignored for testing.
+func (w Watcher) Ignored(p string) bool {
+	return w.ignored(p)
+}
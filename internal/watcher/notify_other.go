@@ -0,0 +1,80 @@
+//go:build !linux
+
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package watcher
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// notify polls paths for changes every second, as inotify is only available on Linux, and sends the path of each
+// change to changes until ctx is done.
+func notify(ctx context.Context, paths []string, changes chan<- string) error {
+	scan := func() map[string]time.Time {
+		files := map[string]time.Time{}
+		for _, p := range paths {
+			_ = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return nil
+				}
+				if fi, err := d.Info(); err == nil {
+					files[path] = fi.ModTime()
+				}
+				return nil
+			})
+		}
+		return files
+	}
+
+	previous := scan()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current := scan()
+		var changed []string
+		for p, t := range current {
+			if o, ok := previous[p]; !ok || !o.Equal(t) {
+				changed = append(changed, p)
+			}
+		}
+		for p := range previous {
+			if _, ok := current[p]; !ok {
+				changed = append(changed, p)
+			}
+		}
+		previous = current
+
+		for _, p := range changed {
+			select {
+			case changes <- p:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
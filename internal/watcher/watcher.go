@@ -0,0 +1,155 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package watcher restarts a child process when files change. It is a minimal alternative to watchexec, accepting
+// the subset of watchexec's arguments that the reload process uses.
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Watcher runs Command, restarting it when anything below Paths changes.
+type Watcher struct {
+	// Command is the command to run and its arguments.
+	Command []string
+
+	// Paths are the files and directories to watch.
+	Paths []string
+
+	// Ignore are glob patterns of paths, relative to the watched path, whose changes are ignored. A pattern matching a
+	// directory name, or ending in /**, ignores everything below that directory.
+	Ignore []string
+
+	// Debounce is the time to wait for further changes before restarting.
+	Debounce time.Duration
+
+	// StopSignal is sent to the child to stop it before restarting.
+	StopSignal syscall.Signal
+
+	// StopTimeout is the grace period the child has to exit after StopSignal before it is killed.
+	StopTimeout time.Duration
+
+	// ClearScreen clears the screen before each restart.
+	ClearScreen bool
+
+	// Signals are forwarded to the child. SIGINT and SIGTERM also stop the watcher.
+	Signals <-chan os.Signal
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Run starts the child and restarts it on changes until the watcher receives SIGINT or SIGTERM, returning the exit
+// code of the child at that point.
+func (w Watcher) Run(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes := make(chan string)
+	failed := make(chan error, 1)
+	go func() {
+		failed <- notify(ctx, w.Paths, changes)
+	}()
+
+	child, err := start(w.Command, w.Stdout, w.Stderr)
+	if err != nil {
+		return 0, err
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			child.stop(w.StopSignal, w.StopTimeout)
+			return child.exitCode(), nil
+
+		case err := <-failed:
+			child.stop(w.StopSignal, w.StopTimeout)
+			if err != nil {
+				return 0, err
+			}
+			return child.exitCode(), nil
+
+		case sig := <-w.Signals:
+			if sig == syscall.SIGINT || sig == syscall.SIGTERM {
+				child.stop(sig, w.StopTimeout)
+				return child.exitCode(), nil
+			}
+			child.signal(sig)
+
+		case p := <-changes:
+			if !w.ignored(p) {
+				debounce = time.After(w.Debounce)
+			}
+
+		case <-debounce:
+			debounce = nil
+			child.stop(w.StopSignal, w.StopTimeout)
+
+			if w.ClearScreen {
+				_, _ = fmt.Fprint(w.Stdout, "\033[H\033[2J")
+			}
+			_, _ = fmt.Fprintf(w.Stderr, "[Restarting: %s]\n", strings.Join(w.Command, " "))
+
+			if child, err = start(w.Command, w.Stdout, w.Stderr); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// ignored reports whether a change to p matches one of the ignore patterns.
+func (w Watcher) ignored(p string) bool {
+	rel := filepath.ToSlash(p)
+	for _, root := range w.Paths {
+		if r, err := filepath.Rel(root, p); err == nil && !strings.HasPrefix(r, "..") {
+			rel = filepath.ToSlash(r)
+			break
+		}
+	}
+
+	elements := strings.Split(rel, "/")
+	for _, pattern := range w.Ignore {
+		pattern = strings.TrimPrefix(pattern, "/")
+
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if rel == prefix || strings.HasPrefix(rel, prefix+"/") {
+				return true
+			}
+			continue
+		}
+
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		for _, e := range elements {
+			if ok, _ := path.Match(pattern, e); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package watcher_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/executable-jar/v6/internal/watcher"
+)
+
+// TestHelperProcess is the fake child process run by the watcher tests. It prints the signals it receives and exits
+// with 3 on SIGTERM, unless it is told to ignore it.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGUSR1)
+	fmt.Println("started")
+
+	for s := range signals {
+		fmt.Println(s)
+		if s == syscall.SIGTERM && os.Getenv("HELPER_IGNORE_SIGTERM") != "1" {
+			os.Exit(3)
+		}
+	}
+}
+
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.buffer.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.buffer.String()
+}
+
+func testWatcher(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect       = NewWithT(t).Expect
+		Eventually   = NewWithT(t).Eventually
+		Consistently = NewWithT(t).Consistently
+
+		path    string
+		signals chan os.Signal
+		stdout  *syncBuffer
+		w       watcher.Watcher
+	)
+
+	run := func(ctx gocontext.Context) chan int {
+		codes := make(chan int, 1)
+		go func() {
+			code, err := w.Run(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			codes <- code
+		}()
+		return codes
+	}
+
+	started := func() int {
+		return strings.Count(stdout.String(), "started")
+	}
+
+	// settle waits for restarts caused by earlier writes to finish, returning the number of times the child started
+	settle := func() int {
+		n := started()
+		Eventually(func() bool {
+			previous := n
+			time.Sleep(100 * time.Millisecond)
+			n = started()
+			return n == previous
+		}, "5s").Should(BeTrue())
+		return n
+	}
+
+	it.Before(func() {
+		t.Setenv("GO_WANT_HELPER_PROCESS", "1")
+
+		path = t.TempDir()
+		signals = make(chan os.Signal)
+		stdout = &syncBuffer{}
+
+		w = watcher.Watcher{
+			Command:     []string{os.Args[0], "-test.run=TestHelperProcess"},
+			Paths:       []string{path},
+			Debounce:    10 * time.Millisecond,
+			StopSignal:  syscall.SIGTERM,
+			StopTimeout: 5 * time.Second,
+			Signals:     signals,
+			Stdout:      stdout,
+			Stderr:      &syncBuffer{},
+		}
+	})
+
+	it("restarts the child when a file changes", func() {
+		ctx, cancel := gocontext.WithCancel(gocontext.Background())
+		codes := run(ctx)
+
+		Eventually(started).Should(Equal(1))
+		Eventually(func() int {
+			Expect(os.WriteFile(filepath.Join(path, "A.class"), []byte{}, 0644)).To(Succeed())
+			return started()
+		}, "5s").Should(BeNumerically(">=", 2))
+		Expect(stdout.String()).To(ContainSubstring("terminated"))
+
+		settle()
+		cancel()
+		Eventually(codes, "5s").Should(Receive(Equal(3)))
+	})
+
+	it("watches directories created after it started", func() {
+		ctx, cancel := gocontext.WithCancel(gocontext.Background())
+		defer cancel()
+		run(ctx)
+
+		Eventually(started).Should(Equal(1))
+		Expect(os.MkdirAll(filepath.Join(path, "com", "example"), 0755)).To(Succeed())
+		Eventually(started, "5s").Should(Equal(2))
+
+		Eventually(func() int {
+			Expect(os.WriteFile(filepath.Join(path, "com", "example", "A.class"), []byte{}, 0644)).To(Succeed())
+			return started()
+		}, "5s").Should(BeNumerically(">=", 3))
+	})
+
+	it("does not restart the child for ignored files", func() {
+		w.Ignore = []string{"*.log"}
+
+		ctx, cancel := gocontext.WithCancel(gocontext.Background())
+		defer cancel()
+		run(ctx)
+
+		Eventually(func() int {
+			Expect(os.WriteFile(filepath.Join(path, "A.class"), []byte{}, 0644)).To(Succeed())
+			return started()
+		}, "5s").Should(BeNumerically(">=", 2))
+
+		n := settle()
+
+		Consistently(func() int {
+			Expect(os.WriteFile(filepath.Join(path, "app.log"), []byte("log"), 0644)).To(Succeed())
+			return started()
+		}, "500ms").Should(Equal(n))
+	})
+
+	it("forwards signals to the child", func() {
+		ctx, cancel := gocontext.WithCancel(gocontext.Background())
+		defer cancel()
+		run(ctx)
+
+		Eventually(started).Should(Equal(1))
+		signals <- syscall.SIGUSR1
+		Eventually(stdout.String).Should(ContainSubstring("user defined signal 1"))
+		Expect(started()).To(Equal(1))
+	})
+
+	it("stops the child and returns its exit code on SIGTERM", func() {
+		codes := run(gocontext.Background())
+
+		Eventually(started).Should(Equal(1))
+		signals <- syscall.SIGTERM
+		Eventually(codes, "5s").Should(Receive(Equal(3)))
+	})
+
+	it("kills the child after the grace period", func() {
+		t.Setenv("HELPER_IGNORE_SIGTERM", "1")
+		w.StopTimeout = 100 * time.Millisecond
+
+		codes := run(gocontext.Background())
+
+		Eventually(started).Should(Equal(1))
+		signals <- syscall.SIGTERM
+		Eventually(codes, "5s").Should(Receive(Equal(128 + int(syscall.SIGKILL))))
+		Expect(stdout.String()).To(ContainSubstring("terminated"))
+	})
+
+	it("keeps watching after the child exits", func() {
+		w.Command = []string{"sh", "-c", "echo started"}
+
+		ctx, cancel := gocontext.WithCancel(gocontext.Background())
+		codes := run(ctx)
+
+		Eventually(started).Should(Equal(1))
+		Eventually(func() int {
+			Expect(os.WriteFile(filepath.Join(path, "A.class"), []byte{}, 0644)).To(Succeed())
+			return started()
+		}, "5s").Should(BeNumerically(">=", 2))
+
+		cancel()
+		Eventually(codes, "5s").Should(Receive())
+	})
+
+	it("returns an error if the command cannot be started", func() {
+		w.Command = []string{filepath.Join(path, "missing")}
+
+		_, err := w.Run(gocontext.Background())
+		Expect(err).To(MatchError(ContainSubstring("unable to start")))
+	})
+}